@@ -17,6 +17,10 @@ const (
 	CollectHeartbeatRouter = "/api/heartbeat/list"
 )
 
+// ErrBadRequest is returned when the api rejects a whole request with a 4xx
+// status. Sending the same heartbeats again would be rejected the same way.
+var ErrBadRequest = errors.New("bad request")
+
 func (c Client) SendHeartbeats(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 	logger := log.Extract(ctx)
 	logger.Debugf("Sending %d heartbeats(s) to api at %s", len(hs), c.baseURL)
@@ -70,11 +74,12 @@ func (c Client) sendHeartbeats(ctx context.Context, url string, hs []heartbeat.H
 	if err != nil {
 		return nil, fmt.Errorf("Failed reading response body from %q: %s", url, err)
 	}
-	switch res.StatusCode {
-	case http.StatusAccepted, http.StatusCreated:
-	case http.StatusBadRequest:
-		return nil, fmt.Errorf("Bad request at %q", url)
-	case http.StatusInternalServerError:
+	switch {
+	case res.StatusCode == http.StatusAccepted, res.StatusCode == http.StatusCreated:
+	case res.StatusCode >= http.StatusBadRequest && res.StatusCode < http.StatusInternalServerError &&
+		res.StatusCode != http.StatusTooManyRequests:
+		return nil, fmt.Errorf("%w at %q. status: %d, body: %q", ErrBadRequest, url, res.StatusCode, string(body))
+	case res.StatusCode == http.StatusInternalServerError:
 		return nil, fmt.Errorf("Server error at %q", url)
 	default:
 		return nil, fmt.Errorf("Invalid response status from %q. got: %d, want: %d/%d. body: %q",
@@ -105,20 +110,33 @@ func ParseHeartbeatResponses(ctx context.Context, data []byte) ([]heartbeat.Resu
 }
 
 func parseHeartbeatResponse(ctx context.Context, data json.RawMessage) (heartbeat.Result, error) {
-	var result heartbeat.Result
-
 	type responseBody struct {
 		Data   *heartbeat.Heartbeat `json:"data"`
 		Status int                  `json:"status"`
+		Error  string               `json:"error"`
 	}
 
-	err := json.Unmarshal(data, &result)
+	var body responseBody
+
+	err := json.Unmarshal(data, &body)
 	if err != nil {
 		return heartbeat.Result{}, fmt.Errorf("Failed to parse json status or heartbeat: %s", err)
 	}
 
-	if result.Status < http.StatusOK || result.Status > 299 {
-		return heartbeat.Result{}, fmt.Errorf("Incorrect status: %d", result.Status)
+	if body.Status < http.StatusOK {
+		return heartbeat.Result{}, fmt.Errorf("Incorrect status: %d", body.Status)
+	}
+
+	result := heartbeat.Result{
+		Status: body.Status,
+	}
+
+	if body.Data != nil {
+		result.Heartbeat = *body.Data
+	}
+
+	if body.Error != "" {
+		result.Errors = append(result.Errors, body.Error)
 	}
 
 	return result, nil
@@ -6,11 +6,14 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	heartbeatAPI "github.com/result17/codeBeatCli/internal/api"
+	"github.com/result17/codeBeatCli/internal/backoff"
+	"github.com/result17/codeBeatCli/internal/platform"
 	hearbeatPkg "github.com/result17/codeBeatCli/pkg/entity"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +35,8 @@ func TestSendHeartbeats(t *testing.T) {
 	v.Set("entity", "testdata/main.go")
 	v.Set("language", "Go")
 	v.Set("alternate-project", "test-cli")
+	v.Set("branch", "main")
+	v.Set("hostname", "dev-laptop")
 	v.Set("lineno", 19)
 	v.Set("lines-in-file", 38)
 	v.Set("plugin", plugin)
@@ -64,7 +69,7 @@ func TestSendHeartbeats(t *testing.T) {
 		expectedBodyStr := fmt.Sprintf(
 			string(fmtStr),
 			entity.Entity,
-			hearbeatPkg.UserAgent(t.Context(), plugin),
+			hearbeatPkg.UserAgent(t.Context(), plugin, platformCacheFilepath(offlineQueueFile.Name())),
 		)
 
 		assert.True(t, strings.HasSuffix(entity.Entity, "testdata/main.go"))
@@ -87,6 +92,151 @@ func TestSendHeartbeats(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestSendHeartbeatsWithExtraHeartbeats(t *testing.T) {
+	testURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var (
+		plugin   = "codebeat/0.0.1"
+		numCalls int
+	)
+
+	v := viper.New()
+	v.Set("api-url", testURL)
+	v.Set("entity", "testdata/main.go")
+	v.Set("extra-heartbeats", true)
+	v.Set("plugin", plugin)
+	v.Set("time", 1585598059100)
+
+	data, err := os.ReadFile("testdata/extra_heartbeats.json")
+	require.NoError(t, err)
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	origStdin := os.Stdin
+	defer func() { os.Stdin = origStdin }()
+	os.Stdin = r
+
+	go func() {
+		_, _ = w.Write(data)
+		w.Close()
+	}()
+
+	offlineQueueFile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+	defer offlineQueueFile.Close()
+
+	router.HandleFunc(heartbeatAPI.CollectHeartbeatRouter, func(w http.ResponseWriter, r *http.Request) {
+		numCalls++
+
+		var hs []struct {
+			Entity    string `json:"entity"`
+			UserAgent string `json:"userAgent"`
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&hs)
+		require.NoError(t, err)
+
+		// the extra heartbeat without entity is skipped
+		require.Len(t, hs, 2)
+		assert.True(t, strings.HasSuffix(hs[0].Entity, "testdata/main.go"))
+		assert.True(t, strings.HasSuffix(hs[1].Entity, "testdata/util.go"))
+		assert.Equal(t, hearbeatPkg.UserAgent(t.Context(), plugin, platformCacheFilepath(offlineQueueFile.Name())), hs[1].UserAgent)
+
+		w.WriteHeader(http.StatusCreated)
+
+		f, err := os.Open("testdata/api_heartbeats_extra_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	err = hearbeatPkg.SendHeartbeats(t.Context(), v, offlineQueueFile.Name())
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
+func TestSendHeartbeatsWithWrite(t *testing.T) {
+	testURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	v := viper.New()
+	v.Set("api-url", testURL)
+	v.Set("entity", "testdata/main.go")
+	v.Set("time", 1585598059100)
+	v.Set("write", true)
+
+	offlineQueueFile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+	defer offlineQueueFile.Close()
+
+	router.HandleFunc(heartbeatAPI.CollectHeartbeatRouter, func(w http.ResponseWriter, r *http.Request) {
+		numCalls++
+
+		var hs []struct {
+			IsWrite bool `json:"isWrite"`
+		}
+
+		err := json.NewDecoder(r.Body).Decode(&hs)
+		require.NoError(t, err)
+
+		require.Len(t, hs, 1)
+		assert.True(t, hs[0].IsWrite)
+
+		w.WriteHeader(http.StatusCreated)
+
+		f, err := os.Open("testdata/api_heartbeats_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	err = hearbeatPkg.SendHeartbeats(t.Context(), v, offlineQueueFile.Name())
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
+func TestSendHeartbeatsBadRequest(t *testing.T) {
+	testURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	v := viper.New()
+	v.Set("api-url", testURL)
+	v.Set("entity", "testdata/main.go")
+	v.Set("time", 1585598059100)
+
+	offlineQueueFile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+	defer offlineQueueFile.Close()
+
+	router.HandleFunc(heartbeatAPI.CollectHeartbeatRouter, func(w http.ResponseWriter, _ *http.Request) {
+		numCalls++
+
+		w.WriteHeader(http.StatusBadRequest)
+		_, err := w.Write([]byte(`{"error":"invalid heartbeats"}`))
+		require.NoError(t, err)
+	})
+
+	err = hearbeatPkg.SendHeartbeats(t.Context(), v, offlineQueueFile.Name())
+	require.ErrorIs(t, err, heartbeatAPI.ErrBadRequest)
+
+	// rejected heartbeats do not count as a failed attempt to reach the api
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(offlineQueueFile.Name()), backoff.Filename))
+
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
 func TestSendHeartbeatsToLocalServer(t *testing.T) {
 	var (
 		plugin = "codebeat/0.0.123"
@@ -138,6 +288,24 @@ func TestSendHeartbeatsToLocalServerWithoutTime(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// platformCacheFilepath returns the platform cache, kept next to the offline
+// queue at queueFilepath.
+func platformCacheFilepath(queueFilepath string) string {
+	return filepath.Join(filepath.Dir(queueFilepath), platform.CacheFilename)
+}
+
+func TestUserAgent_EmptyPlugin(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), platform.CacheFilename)
+
+	for _, plugin := range []string{"", "  "} {
+		userAgent := hearbeatPkg.UserAgent(t.Context(), plugin, fp)
+		assert.True(t, strings.HasSuffix(userAgent, " codeBeat-v0/"), userAgent)
+	}
+
+	userAgent := hearbeatPkg.UserAgent(t.Context(), "vscode/1.99.0", fp)
+	assert.True(t, strings.HasSuffix(userAgent, " vscode/1.99.0"), userAgent)
+}
+
 func TestHeartbeatResults(t *testing.T) {
 	data, err := os.ReadFile("testdata/api_heartbeat_list_response.json")
 	require.NoError(t, err)
@@ -7,13 +7,17 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/result17/codeBeatCli/internal/heartbeat"
 	"github.com/result17/codeBeatCli/internal/metric"
 	"github.com/spf13/viper"
 )
 
 var metricKeyDataTypeMap = map[string]interface{}{
-	"project": metric.MetricRatioData[string]{},
-	"lineno":  metric.MetricRatioData[uint32]{},
+	"project":    metric.MetricRatioData[string]{},
+	"lineno":     metric.MetricRatioData[uint32]{},
+	"entityType": metric.MetricRatioData[string]{},
+	"category":   metric.MetricRatioData[string]{},
+	"hostname":   metric.MetricRatioData[string]{},
 }
 
 var metricKeyParseFuncMap = map[string]func(data []byte) (interface{}, error){
@@ -23,38 +27,23 @@ var metricKeyParseFuncMap = map[string]func(data []byte) (interface{}, error){
 	"lineno": func(data []byte) (interface{}, error) {
 		return ParseIntMetricDurationResponse(data)
 	},
+	"entityType": func(data []byte) (interface{}, error) {
+		return ParseStringMetricDurationResponse(data)
+	},
+	"category": func(data []byte) (interface{}, error) {
+		return ParseStringMetricDurationResponse(data)
+	},
+	"hostname": func(data []byte) (interface{}, error) {
+		return ParseStringMetricDurationResponse(data)
+	},
 }
 
 func QueryTodayMetricDuration[T string | uint32](c *Client, ctx context.Context, v *viper.Viper) (*metric.MetricRatioData[T], error) {
 	metricKey := v.GetString("today-metric-duration")
-	url := fmt.Sprintf("%s/api/metric/duration/today/%s", c.baseURL, metricKey)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("Fail to create request: %s", err)
-	}
-
-	resp, err := c.Do(ctx, req)
-
-	defer resp.Body.Close()
-
-	if err != nil {
-		return nil, fmt.Errorf("Fail to execute request: %s", err)
-	}
-
-	body, err := io.ReadAll(resp.Body)
 
+	body, err := c.getMetric(ctx, fmt.Sprintf("%s/api/metric/duration/today/%s", c.baseURL, metricKey), v)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to read body from %q: %s", url, err)
-	}
-
-	switch resp.StatusCode {
-	case http.StatusOK:
-	case http.StatusBadRequest:
-		return nil, fmt.Errorf("Bad request at %q", url)
-	default:
-		return nil, fmt.Errorf(
-			"Invalid response status from %q. want %d, got %d. body: %q", url, http.StatusOK, resp.StatusCode, string(body),
-		)
+		return nil, err
 	}
 
 	if parseFunc, ok := metricKeyParseFuncMap[metricKey]; ok {
@@ -85,3 +74,60 @@ func ParseIntMetricDurationResponse(data []byte) (*metric.MetricRatioData[uint32
 	}
 	return &body, nil
 }
+
+// QueryTodayChurn fetches today's lines added and removed, per project.
+func QueryTodayChurn(c *Client, ctx context.Context, v *viper.Viper) (*metric.MetricChurnData, error) {
+	body, err := c.getMetric(ctx, fmt.Sprintf("%s/api/metric/churn/today", c.baseURL), v)
+	if err != nil {
+		return nil, err
+	}
+
+	var data metric.MetricChurnData
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("Failed to parse json response: %s. body: %q", err, body)
+	}
+
+	return &data, nil
+}
+
+// getMetric returns the body of a metric query at url.
+func (c *Client) getMetric(ctx context.Context, url string, v *viper.Viper) ([]byte, error) {
+	// restricts metrics to entities of one type, like apps or domains
+	if entityType := v.GetString("entity-type"); entityType != "" {
+		parsed, err := heartbeat.ParseEntityType(entityType)
+		if err != nil {
+			return nil, fmt.Errorf("Fail to parse entity-type: %s", err)
+		}
+		url = fmt.Sprintf("%s?entityType=%s", url, parsed)
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to create request: %s", err)
+	}
+
+	resp, err := c.Do(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to execute request: %s", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read body from %q: %s", url, err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest:
+		return nil, fmt.Errorf("Bad request at %q", url)
+	default:
+		return nil, fmt.Errorf(
+			"Invalid response status from %q. want %d, got %d. body: %q", url, http.StatusOK, resp.StatusCode, string(body),
+		)
+	}
+
+	return body, nil
+}
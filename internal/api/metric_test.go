@@ -46,6 +46,66 @@ func TestQueryTodayMetricDuration(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestQueryTodayMetricDurationByCategory(t *testing.T) {
+	testURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc(fmt.Sprintf("/api/metric/duration/today/%s", "category"), func(w http.ResponseWriter, r *http.Request) {
+		numCalls++
+
+		f, err := os.Open("testdata/api_metric_duration_category_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	v := viper.New()
+	v.Set("api-url", testURL)
+	v.Set("today-metric-duration", "category")
+
+	metric, err := metricPkg.TodayMetricDuration[string](t.Context(), v)
+	require.NoError(t, err)
+	require.Len(t, metric.Ratios, 3)
+	assert.Equal(t, "debugging", metric.Ratios[1].Value)
+	assert.Equal(t, "8 hrs", metric.GrandTotal.Text)
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
+func TestQueryTodayMetricDurationByHostname(t *testing.T) {
+	testURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc(fmt.Sprintf("/api/metric/duration/today/%s", "hostname"), func(w http.ResponseWriter, r *http.Request) {
+		numCalls++
+
+		f, err := os.Open("testdata/api_metric_duration_hostname_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	v := viper.New()
+	v.Set("api-url", testURL)
+	v.Set("today-metric-duration", "hostname")
+
+	metric, err := metricPkg.TodayMetricDuration[string](t.Context(), v)
+	require.NoError(t, err)
+	require.Len(t, metric.Ratios, 3)
+	assert.Equal(t, "build-server", metric.Ratios[1].Value)
+	assert.Equal(t, "8 hrs", metric.GrandTotal.Text)
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
 func TestQueryTodayMetricDurationWithLocalServer(t *testing.T) {
 	v := viper.New()
 	v.Set("api-url", "http://127.0.0.1:3000")
@@ -55,3 +115,38 @@ func TestQueryTodayMetricDurationWithLocalServer(t *testing.T) {
 	require.NoError(t, err)
 	assert.Exactly(t, code, exitcode.Success)
 }
+
+func TestQueryTodayChurn(t *testing.T) {
+	testURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/api/metric/churn/today", func(w http.ResponseWriter, r *http.Request) {
+		numCalls++
+		assert.Equal(t, []string{"application/json"}, r.Header["Accept"])
+
+		f, err := os.Open("testdata/api_metric_churn_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	v := viper.New()
+	v.Set("api-url", testURL)
+	v.Set("today-metric-duration", "churn")
+
+	churn, err := metricPkg.TodayChurn(t.Context(), v)
+	require.NoError(t, err)
+	assert.Equal(t, "churn", churn.Metric)
+	require.Len(t, churn.Churns, 2)
+	assert.Equal(t, "codeBeatCli", churn.Churns[0].Value)
+	assert.EqualValues(t, 214, churn.Churns[0].LinesAdded)
+	assert.EqualValues(t, 87, churn.Churns[0].LinesRemoved)
+	assert.EqualValues(t, 250, churn.LinesAdded)
+	assert.EqualValues(t, 99, churn.LinesRemoved)
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
@@ -1,12 +1,138 @@
 package backoff
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
 	"time"
+
+	"github.com/result17/codeBeatCli/internal/api"
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/statefile"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+const (
+	// Filename is the default backoff state filename.
+	Filename = "backoff_codebeat.json"
+	// factor is the base number of seconds to wait after the first failure.
+	factor = 15
+	// maxBackoffSecs is the maximum number of seconds added to the window per retry.
+	maxBackoffSecs = 3600
 )
 
+// ErrBackoff is returned when heartbeats are not sent, because the backoff window is open.
+var ErrBackoff = errors.New("won't send heartbeat due to backoff")
+
 type Config struct {
 	// At is the time when the first failure happened.
-	At time.Time
+	At time.Time `json:"at"`
 	// Retries is the number of attempts to connect.
-	Retries int
+	Retries int `json:"retries"`
+}
+
+// WithBackoff initializes and returns a heartbeat handle option, which
+// prevents sending heartbeats to the api while the backoff window, persisted
+// in the state file at fp, is open. ErrBackoff is returned in that case.
+func WithBackoff(fp string) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugf("Execute heartbeat backoff algorithm with file %s", fp)
+
+			config, err := LoadConfig(fp)
+			if err != nil {
+				logger.Warnf("Fail to load backoff state: %s", err)
+			}
+
+			if config.ShouldBackoff(time.Now()) {
+				logger.Debugf("Backoff after %d failed attempt(s) since %s", config.Retries, config.At)
+				return nil, ErrBackoff
+			}
+
+			results, err := next(ctx, hs)
+			if errors.Is(err, api.ErrBadRequest) {
+				// the api is reachable, only these heartbeats are rejected
+				logger.Debugf("Not incrementing backoff for rejected heartbeats: %s", err)
+				return nil, err
+			}
+
+			if err != nil {
+				logger.Debugf("Incrementing backoff due to error: %s", err)
+
+				if config.At.IsZero() {
+					config.At = time.Now()
+				}
+				config.Retries++
+
+				if saveErr := config.Save(fp); saveErr != nil {
+					logger.Warnf("Fail to save backoff state: %s", saveErr)
+				}
+
+				return nil, err
+			}
+
+			if !config.At.IsZero() || config.Retries > 0 {
+				logger.Debugln("Reset backoff state after successful request")
+
+				if saveErr := (Config{}).Save(fp); saveErr != nil {
+					logger.Warnf("Fail to reset backoff state: %s", saveErr)
+				}
+			}
+
+			return results, nil
+		}
+	}
+}
+
+// ShouldBackoff reports whether now is still inside the backoff window. The
+// window doubles with each retry, starting at factor seconds, and every retry
+// adds at most maxBackoffSecs to it.
+func (c Config) ShouldBackoff(now time.Time) bool {
+	if c.At.IsZero() || c.Retries < 1 {
+		return false
+	}
+
+	return now.Before(c.nextAttempt())
+}
+
+func (c Config) nextAttempt() time.Time {
+	next := c.At
+
+	for i := 0; i < c.Retries; i++ {
+		secs := maxBackoffSecs
+		if i < 12 && factor<<i < maxBackoffSecs {
+			secs = factor << i
+		}
+
+		next = next.Add(time.Duration(secs) * time.Second)
+	}
+
+	return next
+}
+
+// LoadConfig reads the backoff state from fp. A missing file yields an empty Config.
+func LoadConfig(fp string) (Config, error) {
+	var config Config
+
+	err := statefile.Read(fp, &config)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read backoff file: %s", err)
+	}
+
+	return config, nil
+}
+
+// Save writes the backoff state to fp.
+func (c Config) Save(fp string) error {
+	if err := statefile.Write(fp, c); err != nil {
+		return fmt.Errorf("failed to write backoff file: %s", err)
+	}
+
+	return nil
 }
@@ -0,0 +1,109 @@
+package backoff_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/result17/codeBeatCli/internal/api"
+	"github.com/result17/codeBeatCli/internal/backoff"
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_ShouldBackoff(t *testing.T) {
+	at := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		Config   backoff.Config
+		Now      time.Time
+		Expected bool
+	}{
+		"no failure": {
+			Now: at,
+		},
+		"first retry inside window": {
+			Config:   backoff.Config{At: at, Retries: 1},
+			Now:      at.Add(10 * time.Second),
+			Expected: true,
+		},
+		"first retry after window": {
+			Config: backoff.Config{At: at, Retries: 1},
+			Now:    at.Add(16 * time.Second),
+		},
+		"third retry inside window": {
+			Config:   backoff.Config{At: at, Retries: 3},
+			Now:      at.Add(100 * time.Second),
+			Expected: true,
+		},
+		"capped retries keep extending the window": {
+			Config:   backoff.Config{At: at, Retries: 20},
+			Now:      at.Add(10 * time.Hour),
+			Expected: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Config.ShouldBackoff(test.Now))
+		})
+	}
+}
+
+func TestWithBackoff(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), backoff.Filename)
+
+	var calls int
+	failing := func(_ context.Context, _ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		calls++
+		return nil, errors.New("connection refused")
+	}
+
+	handle := backoff.WithBackoff(fp)(failing)
+
+	_, err := handle(t.Context(), []heartbeat.Heartbeat{{Entity: "/tmp/main.go"}})
+	require.EqualError(t, err, "connection refused")
+
+	config, err := backoff.LoadConfig(fp)
+	require.NoError(t, err)
+	assert.Equal(t, 1, config.Retries)
+	assert.False(t, config.At.IsZero())
+
+	_, err = handle(t.Context(), []heartbeat.Heartbeat{{Entity: "/tmp/main.go"}})
+	require.ErrorIs(t, err, backoff.ErrBackoff)
+	assert.Equal(t, 1, calls)
+
+	// simulate an expired window, the next success resets the state
+	require.NoError(t, backoff.Config{At: time.Now().Add(-time.Hour), Retries: 1}.Save(fp))
+
+	succeeding := func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return []heartbeat.Result{{Status: 201, Heartbeat: hs[0]}}, nil
+	}
+
+	results, err := backoff.WithBackoff(fp)(succeeding)(t.Context(), []heartbeat.Heartbeat{{Entity: "/tmp/main.go"}})
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	config, err = backoff.LoadConfig(fp)
+	require.NoError(t, err)
+	assert.Equal(t, backoff.Config{}, config)
+}
+
+func TestWithBackoff_BadRequest(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), backoff.Filename)
+
+	rejecting := func(_ context.Context, _ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return nil, fmt.Errorf("%w at %q", api.ErrBadRequest, "http://localhost")
+	}
+
+	_, err := backoff.WithBackoff(fp)(rejecting)(t.Context(), []heartbeat.Heartbeat{{Entity: "/tmp/main.go"}})
+	require.ErrorIs(t, err, api.ErrBadRequest)
+
+	config, err := backoff.LoadConfig(fp)
+	require.NoError(t, err)
+	assert.Equal(t, backoff.Config{}, config)
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/result17/codeBeatCli/internal/vipertools"
+	"github.com/result17/codeBeatCli/internal/workspace"
+	"github.com/result17/codeBeatCli/pkg/log"
+	"github.com/spf13/viper"
+)
+
+// defaultFile is the default config filename inside the ~/.codebeat folder.
+const defaultFile = "config.toml"
+
+// FilePath returns the config file passed with --config, or the default
+// ~/.codebeat/config.toml.
+func FilePath(v *viper.Viper) (string, error) {
+	if fp := vipertools.GetString(v, "config"); fp != "" {
+		return fp, nil
+	}
+
+	homedir, err := workspace.CodeBeatHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed getting resource directory: %s", err)
+	}
+
+	return filepath.Join(homedir, ".codebeat", defaultFile), nil
+}
+
+// ReadInConfig loads the config file into v. Config keys mirror the command line
+// flags, which take precedence, and hold sections for settings without a flag.
+// A missing default config file is not an error.
+func ReadInConfig(ctx context.Context, v *viper.Viper) error {
+	logger := log.Extract(ctx)
+
+	fp, err := FilePath(v)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(fp); errors.Is(err, os.ErrNotExist) && !v.IsSet("config") {
+		logger.Debugf("No config file found at %s", fp)
+		return nil
+	}
+
+	v.SetConfigFile(fp)
+
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %q: %s", fp, err)
+	}
+
+	logger.Debugf("Loaded config file %s", fp)
+
+	return nil
+}
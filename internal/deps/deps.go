@@ -0,0 +1,210 @@
+package deps
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+const (
+	// maxFileSize is the size of the largest file parsed for dependencies.
+	maxFileSize = 512 * 1024
+	// maxParseDuration is the time after which parsing a file is given up.
+	maxParseDuration = 200 * time.Millisecond
+	// maxDependencies is the number of dependencies kept per heartbeat.
+	maxDependencies = 1000
+	// maxDependencyLength is the length of the longest dependency kept.
+	maxDependencyLength = 200
+	// maxLineLength is the length of the longest line read from a file.
+	maxLineLength = 64 * 1024
+)
+
+// errTimeout is returned when parsing a file takes longer than maxParseDuration.
+var errTimeout = errors.New("parsing dependencies timed out")
+
+// parser extracts the dependencies from a line of source code. Comments are
+// already stripped.
+type parser interface {
+	Parse(line string) []string
+}
+
+// WithDetection initializes and returns a heartbeat handle option, which parses
+// the dependencies imported by the entity of local file heartbeats, based on
+// their language. Dependencies passed explicitly are kept.
+func WithDetection() heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugln("Execute dependency detection")
+
+			for n, h := range hs {
+				if h.Language == nil || h.Dependencies != nil || !h.IsLocalFile() {
+					continue
+				}
+
+				deps, err := Detect(h.LocalFilepath(), *h.Language)
+				if err != nil {
+					logger.Debugf("Failed to detect dependencies of %s: %s", h.Entity, err)
+				}
+
+				if len(deps) > 0 {
+					hs[n].Dependencies = deps
+				}
+			}
+
+			return next(ctx, hs)
+		}
+	}
+}
+
+// Detect returns the dependencies imported by the file at fp, written in lang.
+// Languages without a parser return nothing. Files larger than maxFileSize are
+// skipped, and parsing stops after maxParseDuration, returning the dependencies
+// found so far along with an error.
+func Detect(fp, lang string) ([]string, error) {
+	p, ok := newParser(lang)
+	if !ok {
+		return nil, nil
+	}
+
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %s", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %s", err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	if info.Size() > maxFileSize {
+		return nil, fmt.Errorf("file size %d exceeds limit of %d bytes", info.Size(), maxFileSize)
+	}
+
+	deadline := time.Now().Add(maxParseDuration)
+	stripper := commentStripper{hash: isHashCommented(lang)}
+
+	var (
+		deps []string
+		seen = map[string]bool{}
+	)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineLength)
+
+	for scanner.Scan() {
+		if time.Now().After(deadline) {
+			return deps, errTimeout
+		}
+
+		line := strings.TrimSpace(stripper.Strip(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		for _, dep := range p.Parse(line) {
+			if dep == "" || len(dep) > maxDependencyLength || seen[dep] {
+				continue
+			}
+
+			seen[dep] = true
+			deps = append(deps, dep)
+
+			if len(deps) == maxDependencies {
+				return deps, nil
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return deps, fmt.Errorf("failed to read file: %s", err)
+	}
+
+	return deps, nil
+}
+
+func newParser(lang string) (parser, bool) {
+	switch strings.ToLower(lang) {
+	case "go":
+		return &goParser{}, true
+	case "python":
+		return pythonParser{}, true
+	case "javascript", "typescript":
+		return javascriptParser{}, true
+	case "rust":
+		return rustParser{}, true
+	case "java":
+		return javaParser{}, true
+	case "c", "c++", "objective-c", "objective-c++":
+		return cParser{}, true
+	default:
+		return nil, false
+	}
+}
+
+func isHashCommented(lang string) bool {
+	return strings.EqualFold(lang, "python")
+}
+
+// commentStripper removes line and block comments, keeping quoted strings
+// intact. Block comments may span multiple lines.
+type commentStripper struct {
+	// hash marks languages using "#" for line comments instead of "//" and "/* */".
+	hash    bool
+	inBlock bool
+}
+
+// Strip returns line without comments.
+func (s *commentStripper) Strip(line string) string {
+	var (
+		sb    strings.Builder
+		quote byte
+	)
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+
+		switch {
+		case s.inBlock:
+			if strings.HasPrefix(line[i:], "*/") {
+				s.inBlock = false
+				i++
+			}
+		case quote != 0:
+			sb.WriteByte(c)
+
+			if c == '\\' && i+1 < len(line) {
+				i++
+				sb.WriteByte(line[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+			sb.WriteByte(c)
+		case s.hash && c == '#':
+			return sb.String()
+		case !s.hash && strings.HasPrefix(line[i:], "//"):
+			return sb.String()
+		case !s.hash && strings.HasPrefix(line[i:], "/*"):
+			s.inBlock = true
+			i++
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,103 @@
+package deps_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/deps"
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	tests := map[string]struct {
+		Filepath string
+		Language string
+		Expected []string
+	}{
+		"go": {
+			Filepath: "testdata/golang.go",
+			Language: "Go",
+			Expected: []string{"fmt", "os", "strings", "github.com/mattn/go-sqlite3", "github.com/spf13/viper"},
+		},
+		"python": {
+			Filepath: "testdata/python.py",
+			Language: "Python",
+			Expected: []string{"os", "sys", "numpy", "django"},
+		},
+		"typescript": {
+			Filepath: "testdata/javascript.ts",
+			Language: "TypeScript",
+			Expected: []string{"react", "lodash", "@types/react", "@scope/pkg", "fs", "chart.js"},
+		},
+		"rust": {
+			Filepath: "testdata/rust.rs",
+			Language: "Rust",
+			Expected: []string{"serde", "std", "tokio", "regex"},
+		},
+		"java": {
+			Filepath: "testdata/java.java",
+			Language: "Java",
+			Expected: []string{"java.util", "java.util.concurrent", "org.junit", "com.google.common.collect"},
+		},
+		"c++": {
+			Filepath: "testdata/c.cpp",
+			Language: "C++",
+			Expected: []string{"stdio.h", "boost/asio.hpp", "vector"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dependencies, err := deps.Detect(test.Filepath, test.Language)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, dependencies)
+		})
+	}
+}
+
+func TestDetect_UnsupportedLanguage(t *testing.T) {
+	dependencies, err := deps.Detect("testdata/python.py", "Text")
+	require.NoError(t, err)
+
+	assert.Nil(t, dependencies)
+}
+
+func TestDetect_FileTooLarge(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "large.py")
+	require.NoError(t, os.WriteFile(fp, []byte(strings.Repeat("import os\n", 100*1024)), 0644))
+
+	dependencies, err := deps.Detect(fp, "Python")
+	require.Error(t, err)
+
+	assert.Nil(t, dependencies)
+}
+
+func TestWithDetection(t *testing.T) {
+	goLang, pythonLang := "Go", "Python"
+
+	var sent []heartbeat.Heartbeat
+	handle := deps.WithDetection()(func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		sent = hs
+		return nil, nil
+	})
+
+	_, err := handle(t.Context(), []heartbeat.Heartbeat{
+		{Entity: "testdata/python.py", Language: &pythonLang},
+		{Entity: "testdata/golang.go", Language: &goLang, Dependencies: []string{"explicit"}},
+		{Entity: "testdata/golang.go"},
+		{Entity: "testdata/python.py", Language: &pythonLang, EntityType: heartbeat.AppType},
+	})
+	require.NoError(t, err)
+	require.Len(t, sent, 4)
+
+	assert.Equal(t, []string{"os", "sys", "numpy", "django"}, sent[0].Dependencies)
+	assert.Equal(t, []string{"explicit"}, sent[1].Dependencies)
+	assert.Nil(t, sent[2].Dependencies)
+	assert.Nil(t, sent[3].Dependencies)
+}
@@ -0,0 +1,202 @@
+package deps
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	goImportRegex       = regexp.MustCompile("^import\\b\\s*(.*)$")
+	goImportSpecRegex   = regexp.MustCompile("^(?:[\\w.]+\\s+)?[\"`]([^\"`]+)[\"`]")
+	pythonImportRegex   = regexp.MustCompile(`^import\s+(.+)$`)
+	pythonFromRegex     = regexp.MustCompile(`^from\s+(\S+)\s+import\b`)
+	jsFromRegex         = regexp.MustCompile(`\bfrom\s*['"]([^'"]+)['"]`)
+	jsSideEffectRegex   = regexp.MustCompile(`^import\s*['"]([^'"]+)['"]`)
+	jsRequireRegex      = regexp.MustCompile(`\b(?:require|import)\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+	rustUseRegex        = regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?use\s+(?:::)?([A-Za-z_]\w*)`)
+	rustExternRegex     = regexp.MustCompile(`^extern\s+crate\s+([A-Za-z_]\w*)`)
+	javaImportRegex     = regexp.MustCompile(`^import\s+(static\s+)?([\w.]+(?:\.\*)?)\s*;`)
+	cIncludeRegex       = regexp.MustCompile(`^#\s*(?:include|import)\s*<([^>]+)>`)
+	rustLocalCrates     = map[string]bool{"crate": true, "self": true, "super": true}
+	pythonModulePrefix  = regexp.MustCompile(`^[A-Za-z_]\w*`)
+	jsPackageNameRegex  = regexp.MustCompile(`^(@[^/]+/[^/]+|[^/]+)`)
+	pythonAliasSplitter = regexp.MustCompile(`\s+as\s+`)
+)
+
+// goParser parses import declarations, including parenthesized import blocks.
+type goParser struct {
+	inBlock bool
+}
+
+// Parse implements parser interface.
+func (p *goParser) Parse(line string) []string {
+	if !p.inBlock {
+		match := goImportRegex.FindStringSubmatch(line)
+		if match == nil {
+			return nil
+		}
+
+		line = strings.TrimSpace(match[1])
+		if !strings.HasPrefix(line, "(") {
+			return submatches(goImportSpecRegex, line)
+		}
+
+		p.inBlock = true
+		line = line[1:]
+	}
+
+	var deps []string
+
+	for _, spec := range strings.Split(line, ";") {
+		spec = strings.TrimSpace(spec)
+
+		if strings.HasPrefix(spec, ")") {
+			p.inBlock = false
+			break
+		}
+
+		deps = append(deps, submatches(goImportSpecRegex, spec)...)
+
+		if strings.HasSuffix(spec, ")") {
+			p.inBlock = false
+			break
+		}
+	}
+
+	return deps
+}
+
+// pythonParser parses import and from-import statements, returning top level
+// modules. Relative imports are skipped.
+type pythonParser struct{}
+
+// Parse implements parser interface.
+func (pythonParser) Parse(line string) []string {
+	if match := pythonFromRegex.FindStringSubmatch(line); match != nil {
+		return pythonModules(match[1])
+	}
+
+	match := pythonImportRegex.FindStringSubmatch(line)
+	if match == nil {
+		return nil
+	}
+
+	var deps []string
+
+	for _, name := range strings.Split(strings.Trim(match[1], "()\\ "), ",") {
+		name = pythonAliasSplitter.Split(strings.TrimSpace(name), 2)[0]
+		deps = append(deps, pythonModules(name)...)
+	}
+
+	return deps
+}
+
+func pythonModules(name string) []string {
+	if strings.HasPrefix(name, ".") {
+		return nil
+	}
+
+	if module := pythonModulePrefix.FindString(name); module != "" {
+		return []string{module}
+	}
+
+	return nil
+}
+
+// javascriptParser parses es module imports and exports, dynamic imports and
+// require calls, returning package names. Relative imports are skipped.
+type javascriptParser struct{}
+
+// Parse implements parser interface.
+func (javascriptParser) Parse(line string) []string {
+	var deps []string
+
+	for _, re := range []*regexp.Regexp{jsFromRegex, jsSideEffectRegex, jsRequireRegex} {
+		for _, specifier := range submatches(re, line) {
+			if pkg := javascriptPackage(specifier); pkg != "" {
+				deps = append(deps, pkg)
+			}
+		}
+	}
+
+	return deps
+}
+
+// javascriptPackage returns the package name of an import specifier, like
+// "lodash" for "lodash/map" and "@scope/pkg" for "@scope/pkg/sub".
+func javascriptPackage(specifier string) string {
+	if strings.HasPrefix(specifier, ".") || strings.HasPrefix(specifier, "/") {
+		return ""
+	}
+
+	if strings.Contains(specifier, "://") {
+		return specifier
+	}
+
+	specifier = strings.TrimPrefix(specifier, "node:")
+
+	return jsPackageNameRegex.FindString(specifier)
+}
+
+// rustParser parses use declarations and extern crates, returning crate names.
+// Paths relative to the current crate are skipped.
+type rustParser struct{}
+
+// Parse implements parser interface.
+func (rustParser) Parse(line string) []string {
+	var deps []string
+
+	for _, re := range []*regexp.Regexp{rustUseRegex, rustExternRegex} {
+		for _, name := range submatches(re, line) {
+			if !rustLocalCrates[name] {
+				deps = append(deps, name)
+			}
+		}
+	}
+
+	return deps
+}
+
+// javaParser parses import declarations, returning the imported packages.
+type javaParser struct{}
+
+// Parse implements parser interface.
+func (javaParser) Parse(line string) []string {
+	match := javaImportRegex.FindStringSubmatch(line)
+	if match == nil {
+		return nil
+	}
+
+	parts := strings.Split(match[2], ".")
+
+	// drop the class, and the member of static imports
+	drop := 1
+	if match[1] != "" {
+		drop = 2
+	}
+
+	if len(parts) > drop {
+		parts = parts[:len(parts)-drop]
+	}
+
+	return []string{strings.Join(parts, ".")}
+}
+
+// cParser parses system includes. Quoted includes are project headers, so they
+// are skipped.
+type cParser struct{}
+
+// Parse implements parser interface.
+func (cParser) Parse(line string) []string {
+	return submatches(cIncludeRegex, line)
+}
+
+func submatches(re *regexp.Regexp, s string) []string {
+	var values []string
+
+	for _, match := range re.FindAllStringSubmatch(s, -1) {
+		values = append(values, strings.TrimSpace(match[1]))
+	}
+
+	return values
+}
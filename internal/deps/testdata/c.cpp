@@ -0,0 +1,7 @@
+#include <stdio.h>
+#include <boost/asio.hpp>
+#  include <vector>
+#include "local.h"
+// #include <commented.h>
+/* #include <block.h> */
+int main() { return 0; }
@@ -0,0 +1,20 @@
+package main
+
+import "fmt"
+
+import (
+	"os"
+	str "strings"
+
+	// "commented/out"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/viper"
+)
+
+/*
+import "in/block/comment"
+*/
+
+func main() {
+	fmt.Println(os.Args, str.ToUpper("import \"not/an/import\""), viper.New())
+}
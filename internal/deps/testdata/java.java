@@ -0,0 +1,8 @@
+package com.example;
+
+import java.util.List;
+import java.util.concurrent.*;
+import static org.junit.Assert.assertEquals;
+import com.google.common.collect.ImmutableList;
+
+public class Main {}
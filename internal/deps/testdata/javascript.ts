@@ -0,0 +1,12 @@
+import React, { useState } from 'react';
+import {
+  map,
+  filter,
+} from "lodash/fp";
+import './styles.css';
+import type { Props } from '@types/react/index';
+export { default } from '@scope/pkg/sub';
+import { helper } from '../helper';
+const fs = require('node:fs');
+const lazy = () => import('chart.js');
+// import commented from 'commented';
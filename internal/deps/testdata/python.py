@@ -0,0 +1,10 @@
+#!/usr/bin/env python
+import os, sys as system
+import numpy.linalg as la
+from django.db import models
+from . import utils
+from .helpers import helper
+# import commented
+
+def main():
+    print("import inside string")
@@ -0,0 +1,8 @@
+extern crate serde;
+
+use std::collections::HashMap;
+use tokio::sync::mpsc;
+pub(crate) use crate::config::Config;
+use super::util;
+use ::regex::Regex;
+// use commented::Thing;
@@ -0,0 +1,115 @@
+package diff
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrTooManyChanges is returned when the edit distance exceeds the limit.
+var ErrTooManyChanges = errors.New("too many changes to diff")
+
+// CountLines returns the number of lines added and removed from a to b, using
+// the minimal edit script found by the Myers algorithm. Line endings are
+// ignored. The work is capped to maxEdits added and removed lines, beyond which
+// ErrTooManyChanges is returned.
+func CountLines(a, b []byte, maxEdits int) (added, removed int, err error) {
+	x, y := lineIDs(splitLines(a), splitLines(b))
+
+	// common prefix and suffix are not part of the edit script
+	for len(x) > 0 && len(y) > 0 && x[0] == y[0] {
+		x, y = x[1:], y[1:]
+	}
+
+	for len(x) > 0 && len(y) > 0 && x[len(x)-1] == y[len(y)-1] {
+		x, y = x[:len(x)-1], y[:len(y)-1]
+	}
+
+	d, err := editDistance(x, y, maxEdits)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// every edit is either an added or a removed line
+	delta := len(y) - len(x)
+
+	return (d + delta) / 2, (d - delta) / 2, nil
+}
+
+// editDistance returns the length of the shortest edit script from x to y,
+// which only consists of insertions and deletions.
+func editDistance(x, y []int, maxEdits int) (int, error) {
+	n, m := len(x), len(y)
+
+	if n == 0 || m == 0 {
+		if n+m > maxEdits {
+			return 0, ErrTooManyChanges
+		}
+
+		return n + m, nil
+	}
+
+	limit := min(n+m, maxEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	for d := 0; d <= limit; d++ {
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				i = v[offset+k+1]
+			} else {
+				i = v[offset+k-1] + 1
+			}
+
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+
+			v[offset+k] = i
+
+			if i >= n && j >= m {
+				return d, nil
+			}
+		}
+	}
+
+	return 0, ErrTooManyChanges
+}
+
+func splitLines(data []byte) [][]byte {
+	if len(data) == 0 {
+		return nil
+	}
+
+	lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	for n, line := range lines {
+		lines[n] = bytes.TrimSuffix(line, []byte("\r"))
+	}
+
+	return lines
+}
+
+// lineIDs maps the lines of a and b to integers, which are equal for equal lines.
+func lineIDs(a, b [][]byte) ([]int, []int) {
+	ids := map[string]int{}
+
+	toIDs := func(lines [][]byte) []int {
+		result := make([]int, len(lines))
+
+		for n, line := range lines {
+			id, ok := ids[string(line)]
+			if !ok {
+				id = len(ids)
+				ids[string(line)] = id
+			}
+
+			result[n] = id
+		}
+
+		return result
+	}
+
+	return toIDs(a), toIDs(b)
+}
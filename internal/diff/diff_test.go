@@ -0,0 +1,45 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountLines(t *testing.T) {
+	tests := map[string]struct {
+		A, B           string
+		Added, Removed int
+	}{
+		"unchanged":         {A: "a\nb\nc\n", B: "a\nb\nc\n"},
+		"added":             {A: "a\nc\n", B: "a\nb\nc\n", Added: 1},
+		"removed":           {A: "a\nb\nc\n", B: "a\nc\n", Removed: 1},
+		"modified":          {A: "a\nb\nc\n", B: "a\nB\nc\n", Added: 1, Removed: 1},
+		"new file":          {B: "a\nb\n", Added: 2},
+		"emptied file":      {A: "a\nb\n", Removed: 2},
+		"line endings":      {A: "a\r\nb\r\n", B: "a\nb"},
+		"moved line":        {A: "a\nb\nc\nd\n", B: "b\nc\nd\na\n", Added: 1, Removed: 1},
+		"interleaved edits": {A: "a\nb\nc\nd\ne\n", B: "x\nb\ny\nd\nz\ne\n", Added: 3, Removed: 2},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			added, removed, err := diff.CountLines([]byte(test.A), []byte(test.B), 100)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Added, added)
+			assert.Equal(t, test.Removed, removed)
+		})
+	}
+}
+
+func TestCountLines_TooManyChanges(t *testing.T) {
+	a := strings.Repeat("a\n", 50)
+	b := strings.Repeat("b\n", 50)
+
+	_, _, err := diff.CountLines([]byte(a), []byte(b), 10)
+	assert.ErrorIs(t, err, diff.ErrTooManyChanges)
+}
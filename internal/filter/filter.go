@@ -0,0 +1,123 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+// ProjectFilename is the file marking a folder hierarchy as tracked, when
+// Config.IncludeOnlyWithProjectFile is set.
+const ProjectFilename = ".codebeat-project"
+
+// ErrSkipped is returned when all heartbeats were skipped by filters.
+var ErrSkipped = errors.New("all heartbeats were skipped by filters")
+
+type Config struct {
+	// Exclude skips heartbeats whose entity or project matches any of the patterns.
+	Exclude []*regexp.Regexp
+	// Include skips heartbeats whose entity or project matches none of the
+	// patterns, if there are any.
+	Include []*regexp.Regexp
+	// IncludeOnlyWithProjectFile skips file heartbeats without a ProjectFilename
+	// in the folder of the entity or any of its parents.
+	IncludeOnlyWithProjectFile bool
+}
+
+// WithFiltering initializes and returns a heartbeat handle option, which drops
+// heartbeats matching the filters of config, so they are neither sent nor
+// queued. ErrSkipped is returned if no heartbeat is left.
+func WithFiltering(config Config) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugln("Execute heartbeat filtering")
+
+			var filtered []heartbeat.Heartbeat
+
+			for _, h := range hs {
+				if err := Filter(h, config); err != nil {
+					logger.Infof("Skipping heartbeat for %s: %s", h.Entity, err)
+					continue
+				}
+
+				filtered = append(filtered, h)
+			}
+
+			if len(filtered) == 0 {
+				return nil, ErrSkipped
+			}
+
+			return next(ctx, filtered)
+		}
+	}
+}
+
+// Filter returns an error describing why h is skipped by config, or nil if it is kept.
+func Filter(h heartbeat.Heartbeat, config Config) error {
+	for _, pattern := range config.Exclude {
+		if matchesEntityOrProject(h, pattern) {
+			return fmt.Errorf("matches exclude pattern %q", pattern)
+		}
+	}
+
+	if len(config.Include) > 0 {
+		var included bool
+
+		for _, pattern := range config.Include {
+			if matchesEntityOrProject(h, pattern) {
+				included = true
+				break
+			}
+		}
+
+		if !included {
+			return errors.New("matches no include pattern")
+		}
+	}
+
+	// only local file entities are located in a folder hierarchy
+	if config.IncludeOnlyWithProjectFile && h.IsLocalFile() && !hasProjectFile(h.Entity) {
+		return fmt.Errorf("no %s file found", ProjectFilename)
+	}
+
+	return nil
+}
+
+func matchesEntityOrProject(h heartbeat.Heartbeat, pattern *regexp.Regexp) bool {
+	if pattern.MatchString(h.Entity) {
+		return true
+	}
+
+	return h.Project != nil && pattern.MatchString(*h.Project)
+}
+
+// hasProjectFile reports whether a ProjectFilename exists in the folder of fp
+// or any of its parents.
+func hasProjectFile(fp string) bool {
+	abs, err := filepath.Abs(fp)
+	if err != nil {
+		return false
+	}
+
+	dir := filepath.Dir(abs)
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ProjectFilename)); err == nil {
+			return true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+
+		dir = parent
+	}
+}
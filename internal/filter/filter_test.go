@@ -0,0 +1,87 @@
+package filter_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/filter"
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	tracked := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tracked, filter.ProjectFilename), nil, 0644))
+
+	project := "client-secret"
+
+	tests := map[string]struct {
+		Heartbeat heartbeat.Heartbeat
+		Config    filter.Config
+		Skipped   bool
+	}{
+		"no filters": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "/home/user/src/main.go"},
+		},
+		"excluded entity": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "/home/user/secret/main.go"},
+			Config:    filter.Config{Exclude: []*regexp.Regexp{regexp.MustCompile("/secret/")}},
+			Skipped:   true,
+		},
+		"excluded project": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "/home/user/src/main.go", Project: &project},
+			Config:    filter.Config{Exclude: []*regexp.Regexp{regexp.MustCompile("^client-")}},
+			Skipped:   true,
+		},
+		"included": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "/home/user/work/main.go"},
+			Config:    filter.Config{Include: []*regexp.Regexp{regexp.MustCompile("^/home/user/work/")}},
+		},
+		"not included": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "/home/user/src/main.go"},
+			Config:    filter.Config{Include: []*regexp.Regexp{regexp.MustCompile("^/home/user/work/")}},
+			Skipped:   true,
+		},
+		"with project file": {
+			Heartbeat: heartbeat.Heartbeat{Entity: filepath.Join(tracked, "src", "main.go")},
+			Config:    filter.Config{IncludeOnlyWithProjectFile: true},
+		},
+		"without project file": {
+			Heartbeat: heartbeat.Heartbeat{Entity: filepath.Join(t.TempDir(), "main.go")},
+			Config:    filter.Config{IncludeOnlyWithProjectFile: true},
+			Skipped:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := filter.Filter(test.Heartbeat, test.Config)
+			assert.Equal(t, test.Skipped, err != nil)
+		})
+	}
+}
+
+func TestWithFiltering(t *testing.T) {
+	config := filter.Config{Exclude: []*regexp.Regexp{regexp.MustCompile("/secret/")}}
+
+	var sent []heartbeat.Heartbeat
+	handle := filter.WithFiltering(config)(func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		sent = append(sent, hs...)
+		return nil, nil
+	})
+
+	_, err := handle(t.Context(), []heartbeat.Heartbeat{
+		{Entity: "/home/user/secret/main.go"},
+		{Entity: "/home/user/src/main.go"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []heartbeat.Heartbeat{{Entity: "/home/user/src/main.go"}}, sent)
+
+	_, err = handle(t.Context(), []heartbeat.Heartbeat{{Entity: "/home/user/secret/util.go"}})
+	require.ErrorIs(t, err, filter.ErrSkipped)
+	assert.Len(t, sent, 1)
+}
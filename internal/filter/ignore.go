@@ -0,0 +1,422 @@
+package filter
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/statefile"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+const (
+	// IgnoreFilename is the per directory file holding gitignore style patterns
+	// of entities, which are not tracked.
+	IgnoreFilename = ".codebeatignore"
+	// IgnoreCacheFilename is the default filename of the parsed ignore file cache.
+	IgnoreCacheFilename = "ignore_cache_codebeat.json"
+)
+
+type (
+	ignorePattern struct {
+		// Pattern is the line of the ignore file.
+		Pattern string `json:"pattern"`
+		// Regex matches paths relative to the folder of the ignore file.
+		Regex   string `json:"regex"`
+		Negate  bool   `json:"negate,omitempty"`
+		DirOnly bool   `json:"dirOnly,omitempty"`
+
+		re *regexp.Regexp
+	}
+
+	ignoreFile struct {
+		ModTime  int64           `json:"modTime"`
+		Size     int64           `json:"size"`
+		Patterns []ignorePattern `json:"patterns"`
+	}
+
+	// ignoreCache holds parsed ignore files by path, which are reused as long
+	// as modification time and size are unchanged.
+	ignoreCache struct {
+		Files map[string]*ignoreFile `json:"files"`
+
+		dirty bool
+	}
+)
+
+// WithIgnoreFiles initializes and returns a heartbeat handle option, which drops
+// heartbeats ignored by the .codebeatignore files found from the folder of the
+// entity up to its project root. Parsed ignore files are cached in the file at
+// cacheFilepath. ErrSkipped is returned if no heartbeat is left.
+func WithIgnoreFiles(cacheFilepath string) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugf("Execute ignore file filtering with cache %s", cacheFilepath)
+
+			cache, err := loadIgnoreCache(cacheFilepath)
+			if err != nil {
+				logger.Debugf("Fail to load ignore file cache: %s", err)
+			}
+
+			var filtered []heartbeat.Heartbeat
+
+			for _, h := range hs {
+				if ignoredBy := cache.ignoredBy(ctx, h); ignoredBy != "" {
+					logger.Infof("Skipping heartbeat for %s: ignored by %s", h.Entity, ignoredBy)
+					continue
+				}
+
+				filtered = append(filtered, h)
+			}
+
+			if cache.dirty {
+				if err := cache.save(cacheFilepath); err != nil {
+					logger.Debugf("Fail to save ignore file cache: %s", err)
+				}
+			}
+
+			if len(filtered) == 0 {
+				return nil, ErrSkipped
+			}
+
+			return next(ctx, filtered)
+		}
+	}
+}
+
+// ignoredBy returns the ignore file pattern, which ignores the entity of h, or
+// an empty string. Only local file entities can be ignored.
+func (c *ignoreCache) ignoredBy(ctx context.Context, h heartbeat.Heartbeat) string {
+	if !h.IsLocalFile() {
+		return ""
+	}
+
+	entity, err := filepath.Abs(h.Entity)
+	if err != nil {
+		return ""
+	}
+
+	dirs := ignoreDirs(entity, h.ProjectPath)
+
+	type ruleSet struct {
+		dir      string
+		fp       string
+		patterns []ignorePattern
+	}
+
+	var rules []ruleSet
+
+	// ignore files closer to the entity take precedence, so they are applied last
+	for i := len(dirs) - 1; i >= 0; i-- {
+		fp := filepath.Join(dirs[i], IgnoreFilename)
+
+		patterns := c.patterns(ctx, fp)
+		if len(patterns) > 0 {
+			rules = append(rules, ruleSet{dir: dirs[i], fp: fp, patterns: patterns})
+		}
+	}
+
+	if len(rules) == 0 {
+		return ""
+	}
+
+	// a file is ignored, if any of its parent folders is ignored
+	parts := strings.Split(filepath.ToSlash(entity), "/")
+
+	for n := 1; n <= len(parts); n++ {
+		candidate := strings.Join(parts[:n], "/")
+		isDir := n < len(parts)
+
+		var ignoredBy string
+
+		for _, rule := range rules {
+			rel, ok := relativeTo(filepath.ToSlash(rule.dir), candidate)
+			if !ok {
+				continue
+			}
+
+			for _, pattern := range rule.patterns {
+				if pattern.DirOnly && !isDir {
+					continue
+				}
+
+				if !pattern.re.MatchString(rel) {
+					continue
+				}
+
+				ignoredBy = ""
+				if !pattern.Negate {
+					ignoredBy = fmt.Sprintf("%s (%s)", rule.fp, pattern.Pattern)
+				}
+			}
+		}
+
+		if ignoredBy != "" {
+			return ignoredBy
+		}
+	}
+
+	return ""
+}
+
+// ignoreDirs returns the folders from the one of entity up to projectPath. If
+// the entity is outside of projectPath, only its own folder is returned.
+func ignoreDirs(entity string, projectPath *string) []string {
+	dir := filepath.Dir(entity)
+	dirs := []string{dir}
+
+	if projectPath == nil {
+		return dirs
+	}
+
+	root, err := filepath.Abs(*projectPath)
+	if err != nil {
+		return dirs
+	}
+
+	if _, ok := relativeTo(filepath.ToSlash(root), filepath.ToSlash(dir)); !ok {
+		return dirs
+	}
+
+	for dir != root {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+
+		dir = parent
+		dirs = append(dirs, dir)
+	}
+
+	return dirs
+}
+
+// relativeTo returns fp relative to dir, if fp is inside of dir.
+func relativeTo(dir, fp string) (string, bool) {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+
+	rel, ok := strings.CutPrefix(fp, prefix)
+	if !ok || rel == "" {
+		return "", false
+	}
+
+	return rel, true
+}
+
+// patterns returns the patterns of the ignore file at fp, parsing it only if it
+// changed since it was cached.
+func (c *ignoreCache) patterns(ctx context.Context, fp string) []ignorePattern {
+	logger := log.Extract(ctx)
+
+	info, err := os.Stat(fp)
+	if err != nil {
+		if _, ok := c.Files[fp]; ok {
+			delete(c.Files, fp)
+			c.dirty = true
+		}
+
+		return nil
+	}
+
+	cached, ok := c.Files[fp]
+	if ok && cached.ModTime == info.ModTime().UnixNano() && cached.Size == info.Size() {
+		return cached.Patterns
+	}
+
+	f, err := os.Open(fp)
+	if err != nil {
+		logger.Warnf("Fail to open ignore file %s: %s", fp, err)
+		return nil
+	}
+	defer f.Close()
+
+	patterns, err := parseIgnoreFile(f)
+	if err != nil {
+		logger.Warnf("Fail to parse ignore file %s: %s", fp, err)
+		return nil
+	}
+
+	c.Files[fp] = &ignoreFile{
+		ModTime:  info.ModTime().UnixNano(),
+		Size:     info.Size(),
+		Patterns: patterns,
+	}
+	c.dirty = true
+
+	return patterns
+}
+
+func loadIgnoreCache(fp string) (*ignoreCache, error) {
+	cache := &ignoreCache{Files: map[string]*ignoreFile{}}
+
+	var loaded ignoreCache
+
+	err := statefile.Read(fp, &loaded)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+
+	if err != nil {
+		return cache, fmt.Errorf("failed to read ignore cache file: %s", err)
+	}
+
+	for path, file := range loaded.Files {
+		if file == nil {
+			continue
+		}
+
+		valid := true
+
+		for n := range file.Patterns {
+			re, err := regexp.Compile(file.Patterns[n].Regex)
+			if err != nil {
+				valid = false
+				break
+			}
+
+			file.Patterns[n].re = re
+		}
+
+		if valid {
+			cache.Files[path] = file
+		}
+	}
+
+	return cache, nil
+}
+
+func (c *ignoreCache) save(fp string) error {
+	if err := statefile.Write(fp, c); err != nil {
+		return fmt.Errorf("failed to write ignore cache file: %s", err)
+	}
+
+	return nil
+}
+
+// parseIgnoreFile reads gitignore style patterns from r.
+func parseIgnoreFile(r io.Reader) ([]ignorePattern, error) {
+	var patterns []ignorePattern
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		pattern, ok := parseIgnorePattern(scanner.Text())
+		if ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	return patterns, scanner.Err()
+}
+
+// parseIgnorePattern parses a single line of an ignore file, following the
+// gitignore syntax: "#" starts a comment, "!" negates, a trailing "/" matches
+// folders only, and patterns containing a "/" are relative to the ignore file,
+// while others match at any depth.
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	source := line
+
+	// trailing spaces are ignored unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	var pattern ignorePattern
+
+	if strings.HasPrefix(line, "!") {
+		pattern.Negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		pattern.DirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr := "^"
+	if !anchored {
+		expr += "(?:.*/)?"
+	}
+
+	expr += globToRegex(line) + "$"
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return ignorePattern{}, false
+	}
+
+	pattern.Pattern = strings.TrimSpace(source)
+	pattern.Regex = expr
+	pattern.re = re
+
+	return pattern, true
+}
+
+// globToRegex translates a gitignore glob into a regular expression, where "*"
+// and "?" do not match "/", and "**" matches across folders.
+func globToRegex(glob string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case glob[i:] == "**" && i > 0 && glob[i-1] == '/':
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			for i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+			}
+
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,121 @@
+package filter_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/filter"
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+
+	writeFile(t, filepath.Join(root, filter.IgnoreFilename), ""+
+		"# generated files\n"+
+		"*.gen.go\n"+
+		"!keep.gen.go\n"+
+		"build/\n"+
+		"/docs/*.md\n"+
+		"vendor/**/testdata\n"+
+		`\#notes`+"\n")
+	writeFile(t, filepath.Join(root, "pkg", filter.IgnoreFilename), "!api.gen.go\nlocal.txt\n")
+
+	tests := map[string]struct {
+		Entity  string
+		Ignored bool
+	}{
+		"not ignored":                   {Entity: "main.go"},
+		"glob at any depth":             {Entity: "pkg/sub/types.gen.go", Ignored: true},
+		"negated":                       {Entity: "pkg/keep.gen.go"},
+		"negated by nested ignore file": {Entity: "pkg/api.gen.go"},
+		"nested ignore file":            {Entity: "pkg/local.txt", Ignored: true},
+		"nested pattern outside folder": {Entity: "local.txt"},
+		"directory only":                {Entity: "cmd/build/main.go", Ignored: true},
+		"directory only matches file":   {Entity: "build"},
+		"anchored":                      {Entity: "docs/readme.md", Ignored: true},
+		"anchored not at root":          {Entity: "pkg/docs/readme.md"},
+		"anchored no subfolders":        {Entity: "docs/api/readme.md"},
+		"double star":                   {Entity: "vendor/a/b/testdata/x.json", Ignored: true},
+		"double star zero folders":      {Entity: "vendor/testdata/x.json", Ignored: true},
+		"escaped hash":                  {Entity: "#notes", Ignored: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cache := filepath.Join(t.TempDir(), filter.IgnoreCacheFilename)
+
+			var sent []heartbeat.Heartbeat
+			handle := filter.WithIgnoreFiles(cache)(func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+				sent = append(sent, hs...)
+				return nil, nil
+			})
+
+			h := heartbeat.Heartbeat{
+				Entity:      filepath.Join(root, filepath.FromSlash(test.Entity)),
+				ProjectPath: &root,
+			}
+
+			_, err := handle(t.Context(), []heartbeat.Heartbeat{h})
+			if test.Ignored {
+				require.ErrorIs(t, err, filter.ErrSkipped)
+				assert.Empty(t, sent)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, sent, 1)
+		})
+	}
+}
+
+func TestWithIgnoreFiles_Cache(t *testing.T) {
+	root := t.TempDir()
+	ignoreFile := filepath.Join(root, filter.IgnoreFilename)
+	cache := filepath.Join(t.TempDir(), filter.IgnoreCacheFilename)
+
+	writeFile(t, ignoreFile, "*.log\n")
+
+	handle := filter.WithIgnoreFiles(cache)(func(_ context.Context, _ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return nil, nil
+	})
+
+	h := heartbeat.Heartbeat{Entity: filepath.Join(root, "debug.log")}
+
+	_, err := handle(t.Context(), []heartbeat.Heartbeat{h})
+	require.ErrorIs(t, err, filter.ErrSkipped)
+	assert.FileExists(t, cache)
+
+	// a changed ignore file is parsed again
+	writeFile(t, ignoreFile, "*.tmp\n")
+
+	_, err = handle(t.Context(), []heartbeat.Heartbeat{h})
+	require.NoError(t, err)
+}
+
+func TestWithIgnoreFiles_NullCacheEntry(t *testing.T) {
+	root := t.TempDir()
+	cache := filepath.Join(t.TempDir(), filter.IgnoreCacheFilename)
+
+	writeFile(t, filepath.Join(root, filter.IgnoreFilename), "*.log\n")
+	writeFile(t, cache, `{"files":{"/missing/.codebeatignore":null}}`)
+
+	handle := filter.WithIgnoreFiles(cache)(func(_ context.Context, _ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return nil, nil
+	})
+
+	_, err := handle(t.Context(), []heartbeat.Heartbeat{{Entity: filepath.Join(root, "debug.log")}})
+	require.ErrorIs(t, err, filter.ErrSkipped)
+}
+
+func writeFile(t *testing.T, fp, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(fp), 0755))
+	require.NoError(t, os.WriteFile(fp, []byte(content), 0644))
+}
@@ -0,0 +1,94 @@
+package heartbeat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Category defines the activity category of a heartbeat.
+type Category int
+
+const (
+	// CodingCategory means writing code, which is the default.
+	CodingCategory Category = iota
+	// DebuggingCategory means running code in a debugger.
+	DebuggingCategory
+	// BuildingCategory means compiling or packaging code.
+	BuildingCategory
+	// ReviewingCategory means reviewing code of others.
+	ReviewingCategory
+	// TestingCategory means running or writing tests.
+	TestingCategory
+)
+
+const (
+	codingCategoryString    = "coding"
+	debuggingCategoryString = "debugging"
+	buildingCategoryString  = "building"
+	reviewingCategoryString = "reviewing"
+	testingCategoryString   = "testing"
+)
+
+// ParseCategory parses a category from a string.
+func ParseCategory(s string) (Category, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case codingCategoryString:
+		return CodingCategory, nil
+	case debuggingCategoryString:
+		return DebuggingCategory, nil
+	case buildingCategoryString:
+		return BuildingCategory, nil
+	case reviewingCategoryString:
+		return ReviewingCategory, nil
+	case testingCategoryString:
+		return TestingCategory, nil
+	default:
+		return 0, fmt.Errorf("invalid category %q", s)
+	}
+}
+
+// String implements fmt.Stringer interface.
+func (c Category) String() string {
+	switch c {
+	case CodingCategory:
+		return codingCategoryString
+	case DebuggingCategory:
+		return debuggingCategoryString
+	case BuildingCategory:
+		return buildingCategoryString
+	case ReviewingCategory:
+		return reviewingCategoryString
+	case TestingCategory:
+		return testingCategoryString
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (c Category) MarshalJSON() ([]byte, error) {
+	s := c.String()
+	if s == "" {
+		return nil, fmt.Errorf("invalid category %d", c)
+	}
+
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (c *Category) UnmarshalJSON(v []byte) error {
+	var s string
+	if err := json.Unmarshal(v, &s); err != nil {
+		return err
+	}
+
+	parsed, err := ParseCategory(s)
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package heartbeat_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCategory(t *testing.T) {
+	tests := map[string]heartbeat.Category{
+		"coding":    heartbeat.CodingCategory,
+		"debugging": heartbeat.DebuggingCategory,
+		"Building":  heartbeat.BuildingCategory,
+		"reviewing": heartbeat.ReviewingCategory,
+		"testing":   heartbeat.TestingCategory,
+	}
+
+	for value, expected := range tests {
+		t.Run(value, func(t *testing.T) {
+			parsed, err := heartbeat.ParseCategory(value)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, parsed)
+		})
+	}
+}
+
+func TestParseCategory_Invalid(t *testing.T) {
+	_, err := heartbeat.ParseCategory("meeting")
+	assert.Error(t, err)
+}
+
+func TestCategory_JSON(t *testing.T) {
+	data, err := json.Marshal(heartbeat.Heartbeat{Entity: "main.go", Category: heartbeat.DebuggingCategory})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"category":"debugging"`)
+
+	var h heartbeat.Heartbeat
+	require.NoError(t, json.Unmarshal([]byte(`{"entity":"main.go","category":"testing"}`), &h))
+	assert.Equal(t, heartbeat.TestingCategory, h.Category)
+
+	var invalid heartbeat.Heartbeat
+	assert.Error(t, json.Unmarshal([]byte(`{"entity":"main.go","category":"meeting"}`), &invalid))
+}
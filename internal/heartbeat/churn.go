@@ -0,0 +1,88 @@
+package heartbeat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/result17/codeBeatCli/internal/diff"
+	"github.com/result17/codeBeatCli/internal/vcs"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+const (
+	// maxChurnFileSize is the size of the largest file diffed against HEAD.
+	maxChurnFileSize = 1024 * 1024
+	// maxChurnEdits is the number of changed lines, beyond which diffing a file
+	// is given up.
+	maxChurnEdits = 5000
+)
+
+// WithLineChanges initializes and returns a heartbeat handle option, which
+// counts the lines added and removed in local file heartbeats, compared to the
+// HEAD commit of their git repository. Untracked files are left out.
+func WithLineChanges() HandleOption {
+	return func(next Handle) Handle {
+		return func(ctx context.Context, hs []Heartbeat) ([]Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugln("Execute line changes detection")
+
+			for n, h := range hs {
+				if !h.IsLocalFile() || h.LinesAdded != nil || h.LinesRemoved != nil {
+					continue
+				}
+
+				added, removed, err := countLineChanges(h.Entity, h.LocalFilepath())
+				if err != nil {
+					logger.Debugf("Failed to count line changes of %s: %s", h.Entity, err)
+					continue
+				}
+
+				hs[n].LinesAdded = &added
+				hs[n].LinesRemoved = &removed
+			}
+
+			return next(ctx, hs)
+		}
+	}
+}
+
+// CountLineChanges returns the number of lines added and removed in the file at
+// fp, compared to its content at the HEAD commit of its git repository.
+func CountLineChanges(fp string) (added, removed int, err error) {
+	return countLineChanges(fp, fp)
+}
+
+// countLineChanges compares the file at localFp with the content of fp at HEAD.
+func countLineChanges(fp, localFp string) (added, removed int, err error) {
+	repo, ok := vcs.Find(fp)
+	if !ok || repo.Kind != vcs.Git {
+		return 0, 0, errors.New("not inside a git repository")
+	}
+
+	info, err := os.Stat(localFp)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to stat file: %s", err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return 0, 0, errors.New("not a regular file")
+	}
+
+	if info.Size() > maxChurnFileSize {
+		return 0, 0, fmt.Errorf("file size %d exceeds limit of %d bytes", info.Size(), maxChurnFileSize)
+	}
+
+	head, err := repo.HeadBlob(fp, maxChurnFileSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	current, err := os.ReadFile(localFp)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read file: %s", err)
+	}
+
+	return diff.CountLines(head, current, maxChurnEdits)
+}
@@ -0,0 +1,76 @@
+package heartbeat_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLineChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	root := t.TempDir()
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = root
+		// keep git from reading the config of the user running the tests
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_GLOBAL="+os.DevNull, "GIT_CONFIG_NOSYSTEM=1")
+
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	tracked := filepath.Join(root, "main.go")
+	untracked := filepath.Join(root, "untracked.go")
+
+	git("init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(tracked, []byte("package main\n\nfunc main() {\n\tprintln(\"a\")\n}\n"), 0644))
+	git("add", "main.go")
+	git("commit", "-q", "-m", "initial")
+
+	require.NoError(t, os.WriteFile(tracked, []byte("package main\n\nfunc main() {\n\tprintln(\"b\")\n\tprintln(\"c\")\n}\n"), 0644))
+	require.NoError(t, os.WriteFile(untracked, []byte("package main\n"), 0644))
+
+	preset := 7
+
+	opt := heartbeat.WithLineChanges()
+	h := opt(func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		require.Len(t, hs, 4)
+
+		// one line changed and one added
+		require.NotNil(t, hs[0].LinesAdded)
+		require.NotNil(t, hs[0].LinesRemoved)
+		assert.Equal(t, 2, *hs[0].LinesAdded)
+		assert.Equal(t, 1, *hs[0].LinesRemoved)
+
+		assert.Nil(t, hs[1].LinesAdded)
+		assert.Nil(t, hs[1].LinesRemoved)
+
+		assert.Equal(t, &preset, hs[2].LinesAdded)
+		assert.Nil(t, hs[2].LinesRemoved)
+
+		assert.Nil(t, hs[3].LinesAdded)
+		assert.Nil(t, hs[3].LinesRemoved)
+
+		return []heartbeat.Result{{Status: 201}}, nil
+	})
+
+	result, err := h(t.Context(), []heartbeat.Heartbeat{
+		{Entity: tracked, EntityType: heartbeat.FileType},
+		{Entity: untracked, EntityType: heartbeat.FileType},
+		{Entity: tracked, EntityType: heartbeat.FileType, LinesAdded: &preset},
+		{Entity: "example.com", EntityType: heartbeat.DomainType},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Result{{Status: 201}}, result)
+}
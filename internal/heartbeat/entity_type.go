@@ -0,0 +1,94 @@
+package heartbeat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EntityType defines the type of an entity.
+type EntityType int
+
+const (
+	// FileType represents a file entity, which is the default.
+	FileType EntityType = iota
+	// AppType represents an app entity, like a database GUI.
+	AppType
+	// DomainType represents a domain entity, like docs.example.com.
+	DomainType
+	// URLType represents a url entity.
+	URLType
+	// TerminalType represents a terminal session entity.
+	TerminalType
+)
+
+const (
+	fileTypeString     = "file"
+	appTypeString      = "app"
+	domainTypeString   = "domain"
+	urlTypeString      = "url"
+	terminalTypeString = "terminal"
+)
+
+// ParseEntityType parses an entity type from a string.
+func ParseEntityType(s string) (EntityType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case fileTypeString:
+		return FileType, nil
+	case appTypeString:
+		return AppType, nil
+	case domainTypeString:
+		return DomainType, nil
+	case urlTypeString:
+		return URLType, nil
+	case terminalTypeString:
+		return TerminalType, nil
+	default:
+		return 0, fmt.Errorf("invalid entity type %q", s)
+	}
+}
+
+// String implements fmt.Stringer interface.
+func (t EntityType) String() string {
+	switch t {
+	case FileType:
+		return fileTypeString
+	case AppType:
+		return appTypeString
+	case DomainType:
+		return domainTypeString
+	case URLType:
+		return urlTypeString
+	case TerminalType:
+		return terminalTypeString
+	default:
+		return ""
+	}
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (t EntityType) MarshalJSON() ([]byte, error) {
+	s := t.String()
+	if s == "" {
+		return nil, fmt.Errorf("invalid entity type %d", t)
+	}
+
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (t *EntityType) UnmarshalJSON(v []byte) error {
+	var s string
+	if err := json.Unmarshal(v, &s); err != nil {
+		return err
+	}
+
+	parsed, err := ParseEntityType(s)
+	if err != nil {
+		return err
+	}
+
+	*t = parsed
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package heartbeat_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntityType(t *testing.T) {
+	tests := map[string]heartbeat.EntityType{
+		"file":     heartbeat.FileType,
+		"app":      heartbeat.AppType,
+		"Domain":   heartbeat.DomainType,
+		"url":      heartbeat.URLType,
+		"terminal": heartbeat.TerminalType,
+	}
+
+	for value, expected := range tests {
+		t.Run(value, func(t *testing.T) {
+			parsed, err := heartbeat.ParseEntityType(value)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, parsed)
+		})
+	}
+}
+
+func TestParseEntityType_Invalid(t *testing.T) {
+	_, err := heartbeat.ParseEntityType("folder")
+	assert.Error(t, err)
+}
+
+func TestEntityType_JSON(t *testing.T) {
+	data, err := json.Marshal(heartbeat.Heartbeat{Entity: "DBeaver", EntityType: heartbeat.AppType})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"entityType":"app"`)
+
+	var h heartbeat.Heartbeat
+	require.NoError(t, json.Unmarshal([]byte(`{"entity":"docs.example.com","entityType":"domain"}`), &h))
+	assert.Equal(t, heartbeat.DomainType, h.EntityType)
+
+	// missing entity types default to files
+	var file heartbeat.Heartbeat
+	require.NoError(t, json.Unmarshal([]byte(`{"entity":"main.go"}`), &file))
+	assert.Equal(t, heartbeat.FileType, file.EntityType)
+}
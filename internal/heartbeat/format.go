@@ -2,38 +2,124 @@ package heartbeat
 
 import (
 	"context"
+	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
 
 	"github.com/result17/codeBeatCli/internal/windows"
 	"github.com/result17/codeBeatCli/pkg/log"
 )
 
-func WithFormatting() HandleOption {
+// windowsPathRegex matches paths starting with a drive letter or a UNC prefix.
+var windowsPathRegex = regexp.MustCompile(`^(?:[a-zA-Z]:[\\/]|\\\\)`)
+
+// FormatConfig defines how file paths are normalized.
+type FormatConfig struct {
+	// NoResolveSymlinks keeps symlinks in paths, instead of resolving them to
+	// their target.
+	NoResolveSymlinks bool
+	// TranslateWSLPaths maps windows paths to their location inside WSL, like
+	// C:\Users to /mnt/c/Users, for editors on windows talking to a CLI in WSL.
+	TranslateWSLPaths bool
+}
+
+// WithFormatting initializes and returns a heartbeat handle option, which
+// normalizes the paths of file entities and project paths.
+func WithFormatting(config FormatConfig) HandleOption {
 	return func(next Handle) Handle {
 		return func(ctx context.Context, hs []Heartbeat) ([]Result, error) {
 			logger := log.Extract(ctx)
 			logger.Debugln("Execute heartbeat filepath formatting")
 
 			for n, h := range hs {
-				hs[n] = Format(ctx, h)
+				hs[n] = Format(ctx, h, config)
 			}
 			return next(ctx, hs)
 		}
 	}
 }
 
-func Format(ctx context.Context, h Heartbeat) Heartbeat {
+// Format normalizes the entity and project path of h. Only local file entities
+// are looked up, so other entity types and remote files are kept unchanged.
+func Format(ctx context.Context, h Heartbeat, config FormatConfig) Heartbeat {
+	if h.RemoteHost != nil {
+		return h
+	}
+
+	if h.EntityType == FileType {
+		h.Entity = FormatFilePath(ctx, h.Entity, config)
+	}
+
+	if h.LocalFile != "" {
+		h.LocalFile = FormatFilePath(ctx, h.LocalFile, config)
+	}
+
+	if h.ProjectPath != nil && *h.ProjectPath != "" {
+		projectPath := FormatFilePath(ctx, *h.ProjectPath, config)
+		h.ProjectPath = &projectPath
+	}
+
 	return h
 }
 
-func formatWindowsFilePath(ctx context.Context, h *Heartbeat) {
+// FormatFilePath returns fp with "~" expanded, made absolute and with symlinks
+// resolved, unless disabled by config. Windows paths get upper case drive
+// letters and forward slashes. Windows paths met on other systems can't be
+// looked up, so they are only normalized, unless WSL translation is enabled.
+func FormatFilePath(ctx context.Context, fp string, config FormatConfig) string {
 	logger := log.Extract(ctx)
 
-	formatted, err := filepath.Abs(h.Entity)
+	if config.TranslateWSLPaths {
+		if translated, ok := windows.ToWSLPath(fp); ok {
+			logger.Debugf("Translated %q to WSL path %q", fp, translated)
+			fp = translated
+		}
+	}
+
+	isWindowsPath := windowsPathRegex.MatchString(fp)
+	if isWindowsPath && runtime.GOOS != "windows" {
+		return windows.FormatFilePath(fp)
+	}
+
+	formatted, err := expandHome(fp)
+	if err != nil {
+		logger.Debugf("Failed to expand home folder of %q: %s", fp, err)
+	}
+
+	formatted, err = filepath.Abs(formatted)
+	if err != nil {
+		logger.Debugf("Failed to resolve absolute path for %q: %s", fp, err)
+		return fp
+	}
+
+	if !config.NoResolveSymlinks {
+		resolved, err := filepath.EvalSymlinks(formatted)
+		if err != nil {
+			logger.Debugf("Failed to resolve symlinks of %q: %s", formatted, err)
+		} else {
+			formatted = resolved
+		}
+	}
+
+	if isWindowsPath || runtime.GOOS == "windows" {
+		formatted = windows.FormatFilePath(formatted)
+	}
+
+	return formatted
+}
+
+// expandHome replaces a leading "~" by the home folder of the current user.
+func expandHome(fp string) (string, error) {
+	if fp != "~" && !strings.HasPrefix(fp, "~/") && !strings.HasPrefix(fp, `~\`) {
+		return fp, nil
+	}
+
+	home, err := os.UserHomeDir()
 	if err != nil {
-		logger.Debugf("Failed to resolve absolute path for %q: %s", h.Entity, err)
-		return
+		return fp, err
 	}
 
-	h.Entity = windows.FormatFilePath(formatted)
+	return filepath.Join(home, fp[1:]), nil
 }
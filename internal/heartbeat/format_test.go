@@ -0,0 +1,101 @@
+package heartbeat_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatFilePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix paths")
+	}
+
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	home := filepath.Join(tmp, "home")
+	target := filepath.Join(tmp, "src", "main.go")
+	link := filepath.Join(tmp, "link")
+
+	require.NoError(t, os.MkdirAll(home, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0755))
+	require.NoError(t, os.WriteFile(target, nil, 0644))
+	require.NoError(t, os.Symlink(filepath.Dir(target), link))
+
+	t.Setenv("HOME", home)
+	t.Chdir(tmp)
+
+	tests := map[string]struct {
+		Filepath string
+		Config   heartbeat.FormatConfig
+		Expected string
+	}{
+		"absolute":                   {Filepath: target, Expected: target},
+		"relative":                   {Filepath: "src/main.go", Expected: target},
+		"unclean":                    {Filepath: tmp + "/src/../src//main.go", Expected: target},
+		"home":                       {Filepath: "~/notes.md", Expected: filepath.Join(home, "notes.md")},
+		"home only":                  {Filepath: "~", Expected: home},
+		"not home":                   {Filepath: "~notes.md", Expected: filepath.Join(tmp, "~notes.md")},
+		"symlink":                    {Filepath: filepath.Join(link, "main.go"), Expected: target},
+		"symlink kept":               {Filepath: filepath.Join(link, "main.go"), Config: heartbeat.FormatConfig{NoResolveSymlinks: true}, Expected: filepath.Join(link, "main.go")},
+		"missing file":               {Filepath: "src/missing.go", Expected: filepath.Join(tmp, "src", "missing.go")},
+		"windows path":               {Filepath: `c:\Users\dev\main.go`, Expected: "C:/Users/dev/main.go"},
+		"windows path mixed slashes": {Filepath: `D:/src\\app/main.go`, Expected: "D:/src/app/main.go"},
+		"unc path":                   {Filepath: `\\server\share\main.go`, Expected: "//server/share/main.go"},
+		"wsl drive path":             {Filepath: `C:\Users\dev\main.go`, Config: heartbeat.FormatConfig{TranslateWSLPaths: true}, Expected: "/mnt/c/Users/dev/main.go"},
+		"wsl share path":             {Filepath: `\\wsl$\Ubuntu` + target, Config: heartbeat.FormatConfig{TranslateWSLPaths: true}, Expected: target},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, heartbeat.FormatFilePath(t.Context(), test.Filepath, test.Config))
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix paths")
+	}
+
+	tmp, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	t.Chdir(tmp)
+
+	projectPath := "project/"
+	formattedProjectPath := filepath.Join(tmp, "project")
+
+	tests := map[string]struct {
+		Heartbeat heartbeat.Heartbeat
+		Expected  heartbeat.Heartbeat
+	}{
+		"file": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "project/main.go", ProjectPath: &projectPath},
+			Expected: heartbeat.Heartbeat{
+				Entity:      filepath.Join(tmp, "project", "main.go"),
+				ProjectPath: &formattedProjectPath,
+			},
+		},
+		"app": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "DBeaver", EntityType: heartbeat.AppType},
+			Expected:  heartbeat.Heartbeat{Entity: "DBeaver", EntityType: heartbeat.AppType},
+		},
+		"url": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "https://example.com/docs", EntityType: heartbeat.URLType},
+			Expected:  heartbeat.Heartbeat{Entity: "https://example.com/docs", EntityType: heartbeat.URLType},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, heartbeat.Format(t.Context(), test.Heartbeat, heartbeat.FormatConfig{}))
+		})
+	}
+}
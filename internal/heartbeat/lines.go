@@ -0,0 +1,113 @@
+package heartbeat
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+const (
+	// maxLinesFileSize is the size of the largest file whose lines are counted.
+	maxLinesFileSize = 2 * 1024 * 1024
+	// binarySniffSize is the number of leading bytes looked at for a NUL byte,
+	// which marks binary files, like git does.
+	binarySniffSize = 8000
+)
+
+// ErrBinaryFile is returned when counting the lines of a binary file.
+var ErrBinaryFile = errors.New("binary file")
+
+// WithLinesInFile initializes and returns a heartbeat handle option, which
+// counts the lines of local file heartbeats sent without lines in file. The
+// LocalFile copy of an unsaved buffer is counted, if set.
+func WithLinesInFile() HandleOption {
+	return func(next Handle) Handle {
+		return func(ctx context.Context, hs []Heartbeat) ([]Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugln("Execute lines in file counting")
+
+			for n, h := range hs {
+				if !h.IsLocalFile() || h.LinesInFile != nil {
+					continue
+				}
+
+				lines, err := CountLinesInFile(h.LocalFilepath())
+				if err != nil {
+					logger.Debugf("Failed to count lines of %s: %s", h.LocalFilepath(), err)
+					continue
+				}
+
+				hs[n].LinesInFile = &lines
+			}
+
+			return next(ctx, hs)
+		}
+	}
+}
+
+// CountLinesInFile returns the number of lines of the file at fp, reading it in
+// chunks. A last line without trailing newline is counted. Files larger than
+// maxLinesFileSize are skipped, and ErrBinaryFile is returned for binary files.
+func CountLinesInFile(fp string) (int, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %s", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat file: %s", err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return 0, errors.New("not a regular file")
+	}
+
+	if info.Size() > maxLinesFileSize {
+		return 0, fmt.Errorf("file size %d exceeds limit of %d bytes", info.Size(), maxLinesFileSize)
+	}
+
+	var (
+		buf   = make([]byte, 32*1024)
+		read  int64
+		lines int
+		last  byte
+	)
+
+	for {
+		n, err := f.Read(buf)
+
+		if read < binarySniffSize {
+			sniff := buf[:min(int64(n), binarySniffSize-read)]
+			if bytes.IndexByte(sniff, 0) >= 0 {
+				return 0, ErrBinaryFile
+			}
+		}
+
+		if n > 0 {
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+			read += int64(n)
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return 0, fmt.Errorf("failed to read file: %s", err)
+		}
+	}
+
+	if read > 0 && last != '\n' {
+		lines++
+	}
+
+	return lines, nil
+}
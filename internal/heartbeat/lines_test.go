@@ -0,0 +1,92 @@
+package heartbeat_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountLinesInFile(t *testing.T) {
+	tests := map[string]struct {
+		Content  string
+		Expected int
+	}{
+		"empty":                   {Content: "", Expected: 0},
+		"single line":             {Content: "package main", Expected: 1},
+		"trailing newline":        {Content: "package main\n", Expected: 1},
+		"no trailing newline":     {Content: "package main\n\nfunc main() {}", Expected: 3},
+		"blank lines":             {Content: "\n\n\n", Expected: 3},
+		"crlf":                    {Content: "a\r\nb\r\n", Expected: 2},
+		"larger than read buffer": {Content: strings.Repeat("line\n", 20000), Expected: 20000},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), "main.go")
+			require.NoError(t, os.WriteFile(fp, []byte(test.Content), 0644))
+
+			lines, err := heartbeat.CountLinesInFile(fp)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, lines)
+		})
+	}
+}
+
+func TestCountLinesInFile_Skipped(t *testing.T) {
+	dir := t.TempDir()
+
+	binary := filepath.Join(dir, "image.png")
+	require.NoError(t, os.WriteFile(binary, []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), 0644))
+
+	_, err := heartbeat.CountLinesInFile(binary)
+	assert.ErrorIs(t, err, heartbeat.ErrBinaryFile)
+
+	large := filepath.Join(dir, "large.txt")
+	require.NoError(t, os.WriteFile(large, []byte(strings.Repeat("x", 3*1024*1024)), 0644))
+
+	_, err = heartbeat.CountLinesInFile(large)
+	assert.EqualError(t, err, "file size 3145728 exceeds limit of 2097152 bytes")
+
+	_, err = heartbeat.CountLinesInFile(dir)
+	assert.EqualError(t, err, "not a regular file")
+}
+
+func TestWithLinesInFile(t *testing.T) {
+	dir := t.TempDir()
+
+	entity := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(entity, []byte("package main\n"), 0644))
+
+	unsaved := filepath.Join(dir, "main.go.tmp")
+	require.NoError(t, os.WriteFile(unsaved, []byte("package main\n\nfunc main() {}\n"), 0644))
+
+	lines := 10
+
+	var sent []heartbeat.Heartbeat
+	handle := heartbeat.WithLinesInFile()(func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		sent = append(sent, hs...)
+		return nil, nil
+	})
+
+	_, err := handle(t.Context(), []heartbeat.Heartbeat{
+		{Entity: entity},
+		{Entity: entity, LocalFile: unsaved},
+		{Entity: entity, LinesInFile: &lines},
+		{Entity: "example.com", EntityType: heartbeat.DomainType},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sent, 4)
+	assert.Equal(t, 1, *sent[0].LinesInFile)
+	assert.Equal(t, 3, *sent[1].LinesInFile)
+	assert.Equal(t, entity, sent[1].Entity)
+	assert.Equal(t, 10, *sent[2].LinesInFile)
+	assert.Nil(t, sent[3].LinesInFile)
+}
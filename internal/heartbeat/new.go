@@ -33,15 +33,27 @@ func NewHandle(
 }
 
 type Heartbeat struct {
-	CursorPosition *int    `json:"cursorpos,omitempty"`
-	Entity         string  `json:"entity"`
-	Language       *string `json:"language,omitempty"`
-	LineNumber     *int    `json:"lineno,omitempty"`
-	LinesInFile    *int    `json:"lines,omitempty"`
-	Project        *string `json:"project,omitempty"`
-	ProjectPath    *string `json:"projectPath,omitempty"`
-	Time           uint64  `json:"time"`
-	UserAgent      string  `json:"userAgent"`
+	Branch         *string    `json:"branch,omitempty"`
+	Category       Category   `json:"category"`
+	CursorPosition *int       `json:"cursorpos,omitempty"`
+	Dependencies   []string   `json:"dependencies,omitempty"`
+	Entity         string     `json:"entity"`
+	EntityType     EntityType `json:"entityType"`
+	Hostname       *string    `json:"hostname,omitempty"`
+	IsWrite        bool       `json:"isWrite,omitempty"`
+	Language       *string    `json:"language,omitempty"`
+	LineNumber     *int       `json:"lineno,omitempty"`
+	LinesAdded     *int       `json:"linesAdded,omitempty"`
+	LinesInFile    *int       `json:"lines,omitempty"`
+	LinesRemoved   *int       `json:"linesRemoved,omitempty"`
+	// LocalFile is a temporary copy of an unsaved buffer, read instead of the
+	// entity. It is never sent nor queued.
+	LocalFile   string  `json:"-"`
+	Project     *string `json:"project,omitempty"`
+	ProjectPath *string `json:"projectPath,omitempty"`
+	RemoteHost  *string `json:"remoteHost,omitempty"`
+	Time        uint64  `json:"time"`
+	UserAgent   string  `json:"userAgent"`
 }
 
 func New(entity, userAgent string, time uint64, cursorPos *int, lang *string, lineNum *int, linesInFile *int, project *string, projectPath *string) *Heartbeat {
@@ -60,6 +72,24 @@ func New(entity, userAgent string, time uint64, cursorPos *int, lang *string, li
 	return hb
 }
 
+// IsLocalFile reports whether the entity of h is a file on the local filesystem,
+// which can be read.
+func (h Heartbeat) IsLocalFile() bool {
+	return h.EntityType == FileType && h.RemoteHost == nil
+}
+
+// LocalFilepath returns the file the content of the entity is read from, which
+// is LocalFile if set.
+func (h Heartbeat) LocalFilepath() string {
+	if h.LocalFile != "" {
+		return h.LocalFile
+	}
+
+	return h.Entity
+}
+
+// ID returns the key of h in the offline queue. Saves and other activity at the
+// same millisecond get distinct keys.
 func (h Heartbeat) ID() string {
 	project := "unset"
 	if h.Project != nil {
@@ -76,5 +106,5 @@ func (h Heartbeat) ID() string {
 		lang = *h.Language
 	}
 
-	return fmt.Sprintf("%d-%s-%s-%s-%s", h.Time, lang, h.Entity, cursorPos, project)
+	return fmt.Sprintf("%d-%s-%s-%s-%s-%s-%t", h.Time, lang, h.EntityType, h.Entity, cursorPos, project, h.IsWrite)
 }
@@ -0,0 +1,36 @@
+package heartbeat_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeat_ID(t *testing.T) {
+	h := heartbeat.Heartbeat{Entity: "example.com", Time: 1585598059100}
+
+	domain := h
+	domain.EntityType = heartbeat.DomainType
+
+	write := h
+	write.IsWrite = true
+
+	assert.NotEqual(t, h.ID(), domain.ID())
+	assert.NotEqual(t, h.ID(), write.ID())
+	assert.Equal(t, h.ID(), heartbeat.Heartbeat{Entity: "example.com", Time: 1585598059100}.ID())
+}
+
+func TestHeartbeat_LocalFile(t *testing.T) {
+	h := heartbeat.Heartbeat{Entity: "/home/user/src/main.go", LocalFile: "/tmp/main.go.1234"}
+	assert.Equal(t, "/tmp/main.go.1234", h.LocalFilepath())
+
+	data, err := json.Marshal(h)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "/tmp/main.go.1234")
+
+	h.LocalFile = ""
+	assert.Equal(t, "/home/user/src/main.go", h.LocalFilepath())
+}
@@ -0,0 +1,89 @@
+package heartbeat
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/result17/codeBeatCli/internal/vcs"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+// WithProjectDetection initializes and returns a heartbeat handle option, which
+// detects the project name and root folder from the version control metadata
+// found above the entity. Project and project path passed explicitly are kept.
+func WithProjectDetection() HandleOption {
+	return func(next Handle) Handle {
+		return func(ctx context.Context, hs []Heartbeat) ([]Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugln("Execute project detection")
+
+			for n, h := range hs {
+				hs[n] = DetectProject(ctx, h)
+			}
+
+			return next(ctx, hs)
+		}
+	}
+}
+
+// DetectProject fills in the missing project name, project path and branch of h.
+// Only local file entities can be looked up, so other entity types are detected
+// from the project path, if passed. Remote files only take the project name
+// from their project path.
+func DetectProject(ctx context.Context, h Heartbeat) Heartbeat {
+	if h.Project != nil && h.ProjectPath != nil && h.Branch != nil {
+		return h
+	}
+
+	logger := log.Extract(ctx)
+
+	// the project path of remote entities is no path on the local disk
+	if h.RemoteHost != nil {
+		if h.Project == nil && h.ProjectPath != nil {
+			project := filepath.Base(*h.ProjectPath)
+			h.Project = &project
+		}
+
+		return h
+	}
+
+	fp := h.Entity
+	if !h.IsLocalFile() {
+		if h.ProjectPath == nil {
+			return h
+		}
+
+		fp = *h.ProjectPath
+	}
+
+	repo, ok := vcs.Find(fp)
+	if !ok {
+		if h.Project == nil && h.ProjectPath != nil {
+			project := filepath.Base(*h.ProjectPath)
+			h.Project = &project
+		}
+
+		return h
+	}
+
+	logger.Debugf("Detected %s repository at %s", repo.Kind, repo.Root)
+
+	if h.Project == nil {
+		h.Project = &repo.Name
+	}
+
+	if h.ProjectPath == nil {
+		h.ProjectPath = &repo.Root
+	}
+
+	if h.Branch == nil {
+		branch, err := repo.Branch()
+		if err != nil {
+			logger.Debugf("Failed to detect branch of %s: %s", repo.Root, err)
+		} else {
+			h.Branch = &branch
+		}
+	}
+
+	return h
+}
@@ -0,0 +1,50 @@
+package heartbeat_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectProject(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "prepo")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref: refs/heads/feat\n"), 0644))
+
+	entity := filepath.Join(root, "f.txt")
+	require.NoError(t, os.WriteFile(entity, []byte("text\n"), 0644))
+
+	h := heartbeat.DetectProject(t.Context(), heartbeat.Heartbeat{Entity: entity, EntityType: heartbeat.FileType})
+
+	require.NotNil(t, h.Project)
+	assert.Equal(t, "prepo", *h.Project)
+	require.NotNil(t, h.ProjectPath)
+	assert.Equal(t, root, *h.ProjectPath)
+	require.NotNil(t, h.Branch)
+	assert.Equal(t, "feat", *h.Branch)
+}
+
+func TestDetectProject_RemoteEntity(t *testing.T) {
+	// a local checkout at the same path as the remote project
+	root := filepath.Join(t.TempDir(), "prepo")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref: refs/heads/feat\n"), 0644))
+
+	host := "box"
+
+	h := heartbeat.DetectProject(t.Context(), heartbeat.Heartbeat{
+		Entity:      filepath.Join(root, "f.txt"),
+		EntityType:  heartbeat.FileType,
+		ProjectPath: &root,
+		RemoteHost:  &host,
+	})
+
+	require.NotNil(t, h.Project)
+	assert.Equal(t, "prepo", *h.Project)
+	assert.Equal(t, &root, h.ProjectPath)
+	assert.Nil(t, h.Branch)
+}
@@ -0,0 +1,63 @@
+package heartbeat
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unix timestamps between 2001-09-09 and 2286-11-20 have the same number of
+// digits, so their unit is told apart by magnitude.
+const (
+	minSeconds      = 1e9
+	maxSeconds      = 1e10
+	minMilliseconds = minSeconds * 1e3
+	maxMilliseconds = maxSeconds * 1e3
+	minMicroseconds = minSeconds * 1e6
+	maxMicroseconds = maxSeconds * 1e6
+)
+
+// ParseTime parses a heartbeat time into unix milliseconds. Accepted are
+// RFC3339 strings and unix timestamps in float or integer seconds,
+// milliseconds or microseconds, whose unit is detected by magnitude.
+// Timestamps matching no unit, like 1e11, are ambiguous and return an error.
+func ParseTime(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("empty time")
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		if t.Before(time.Unix(0, 0)) {
+			return 0, fmt.Errorf("time %q is before the unix epoch", s)
+		}
+
+		return uint64(t.UnixMilli()), nil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid time %q, want unix timestamp or RFC3339", s)
+	}
+
+	var ms float64
+
+	switch {
+	case v >= minSeconds && v < maxSeconds:
+		ms = v * 1e3
+	case v >= minMilliseconds && v < maxMilliseconds:
+		ms = v
+	case v >= minMicroseconds && v < maxMicroseconds:
+		ms = v / 1e3
+	default:
+		return 0, fmt.Errorf(
+			"ambiguous time %q, want unix seconds, milliseconds or microseconds between 2001 and 2286",
+			s,
+		)
+	}
+
+	return uint64(math.Round(ms)), nil
+}
@@ -0,0 +1,60 @@
+package heartbeat_test
+
+import (
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTime(t *testing.T) {
+	tests := map[string]uint64{
+		"1585598059":                  1585598059000,
+		"1585598059.1":                1585598059100,
+		" 1585598059.1234 ":           1585598059123,
+		"1585598059100":               1585598059100,
+		"1585598059100.6":             1585598059101,
+		"1585598059100000":            1585598059100,
+		"1585598059100499":            1585598059100,
+		"2020-03-30T19:54:19.1Z":      1585598059100,
+		"2020-03-30T21:54:19+02:00":   1585598059000,
+		"2020-03-30T19:54:19.100123Z": 1585598059100,
+		"1.5855980591e9":              1585598059100,
+		"2020-03-30T19:54:19.000000Z": 1585598059000,
+		"9999999999":                  9999999999000,
+		"1000000000":                  1000000000000,
+	}
+
+	for s, expected := range tests {
+		t.Run(s, func(t *testing.T) {
+			ms, err := heartbeat.ParseTime(s)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, ms)
+		})
+	}
+}
+
+func TestParseTime_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"":                     `empty time`,
+		"yesterday":            `invalid time "yesterday", want unix timestamp or RFC3339`,
+		"NaN":                  `invalid time "NaN", want unix timestamp or RFC3339`,
+		"2020-03-30 19:54:19":  `invalid time "2020-03-30 19:54:19", want unix timestamp or RFC3339`,
+		"1969-12-31T23:59:59Z": `time "1969-12-31T23:59:59Z" is before the unix epoch`,
+		"-1585598059":          `ambiguous time "-1585598059", want unix seconds, milliseconds or microseconds between 2001 and 2286`,
+		"0":                    `ambiguous time "0", want unix seconds, milliseconds or microseconds between 2001 and 2286`,
+		"158559805":            `ambiguous time "158559805", want unix seconds, milliseconds or microseconds between 2001 and 2286`,
+		"158559805910":         `ambiguous time "158559805910", want unix seconds, milliseconds or microseconds between 2001 and 2286`,
+		"158559805910000":      `ambiguous time "158559805910000", want unix seconds, milliseconds or microseconds between 2001 and 2286`,
+		"15855980591000000":    `ambiguous time "15855980591000000", want unix seconds, milliseconds or microseconds between 2001 and 2286`,
+	}
+
+	for s, expected := range tests {
+		t.Run(s, func(t *testing.T) {
+			_, err := heartbeat.ParseTime(s)
+			assert.EqualError(t, err, expected)
+		})
+	}
+}
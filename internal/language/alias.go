@@ -0,0 +1,148 @@
+package language
+
+import (
+	"context"
+	"strings"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+const (
+	vscodeEditor    = "vscode"
+	jetbrainsEditor = "jetbrains"
+)
+
+// editors maps lower case editor names, without spaces, as sent in --plugin to
+// the editor family sharing the same language ids.
+var editors = map[string]string{
+	"androidstudio":   jetbrainsEditor,
+	"clion":           jetbrainsEditor,
+	"cursor":          vscodeEditor,
+	"datagrip":        jetbrainsEditor,
+	"goland":          jetbrainsEditor,
+	"idea":            jetbrainsEditor,
+	"intellij":        jetbrainsEditor,
+	"intellijidea":    jetbrainsEditor,
+	"jetbrains":       jetbrainsEditor,
+	"phpstorm":        jetbrainsEditor,
+	"pycharm":         jetbrainsEditor,
+	"rider":           jetbrainsEditor,
+	"rubymine":        jetbrainsEditor,
+	"rustrover":       jetbrainsEditor,
+	"vscode":          vscodeEditor,
+	"vscode-insiders": vscodeEditor,
+	"vscodium":        vscodeEditor,
+	"webstorm":        jetbrainsEditor,
+	"windsurf":        vscodeEditor,
+}
+
+// aliases maps lower case editor language ids to canonical language names,
+// keyed by editor family.
+var aliases = map[string]map[string]string{
+	vscodeEditor: {
+		"bat":              "Batchfile",
+		"coffeescript":     "CoffeeScript",
+		"cpp":              "C++",
+		"csharp":           "C#",
+		"dockerfile":       "Docker",
+		"dockercompose":    "YAML",
+		"fsharp":           "F#",
+		"javascriptreact":  "JavaScript",
+		"jsonc":            "JSON",
+		"makefile":         "Makefile",
+		"objective-c":      "Objective-C",
+		"objective-cpp":    "Objective-C++",
+		"plaintext":        "Text",
+		"powershell":       "PowerShell",
+		"restructuredtext": "reStructuredText",
+		"shellscript":      "Bash",
+		"typescriptreact":  "TypeScript",
+		"vue":              "Vue.js",
+	},
+	jetbrainsEditor: {
+		"c/c++":          "C++",
+		"dockerfile":     "Docker",
+		"ecmascript 6":   "JavaScript",
+		"go template":    "Go",
+		"jsx harmony":    "JavaScript",
+		"objectivec":     "Objective-C",
+		"plain_text":     "Text",
+		"plaintext":      "Text",
+		"shell script":   "Bash",
+		"typescript jsx": "TypeScript",
+		"vuejs":          "Vue.js",
+	},
+}
+
+// Editor returns the editor family of a plugin string, like
+// "vscode/1.85.0 vscode-codebeat/0.0.1". Unknown editors are returned as parsed.
+func Editor(plugin string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(plugin), "/")
+	name = strings.ToLower(strings.ReplaceAll(name, " ", ""))
+
+	if editor, ok := editors[name]; ok {
+		return editor
+	}
+
+	return name
+}
+
+// WithNormalization initializes and returns a heartbeat handle option, which
+// maps editor specific language ids of the editor parsed from plugin to
+// canonical language names.
+func WithNormalization(plugin string, config Config) heartbeat.HandleOption {
+	editor := Editor(plugin)
+
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugf("Execute language normalization for editor %q", editor)
+
+			for n, h := range hs {
+				if h.Language == nil {
+					continue
+				}
+
+				lang := Normalize(*h.Language, editor, config)
+				hs[n].Language = &lang
+			}
+
+			return next(ctx, hs)
+		}
+	}
+}
+
+// Normalize returns the canonical name of the language id sent by editor.
+// Aliases from config take precedence over the built-in ones, and aliases of
+// the editor over the ones configured for all editors. Unknown ids are
+// returned unchanged.
+func Normalize(id, editor string, config Config) string {
+	key := strings.ToLower(strings.TrimSpace(id))
+
+	for _, table := range []map[string]string{
+		config.Aliases[editor],
+		config.Aliases[""],
+		aliases[editor],
+	} {
+		if lang, ok := lookupAlias(table, key); ok {
+			return lang
+		}
+	}
+
+	if lang, ok := Lookup(key); ok {
+		return lang
+	}
+
+	return id
+}
+
+func lookupAlias(table map[string]string, key string) (string, bool) {
+	for id, lang := range table {
+		if strings.ToLower(id) == key {
+			return lang, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,81 @@
+package language_test
+
+import (
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/language"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditor(t *testing.T) {
+	tests := map[string]string{
+		"vscode/1.85.0 vscode-codebeat/0.0.1": "vscode",
+		"IntelliJ IDEA/2023.2 codebeat/1.0.0": "jetbrains",
+		"GoLand/2024.1":                       "jetbrains",
+		"codebeat/0.0.1":                      "codebeat",
+		"":                                    "",
+	}
+
+	for plugin, expected := range tests {
+		t.Run(plugin, func(t *testing.T) {
+			assert.Equal(t, expected, language.Editor(plugin))
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	config := language.Config{
+		Aliases: map[string]map[string]string{
+			"vscode": {"typescriptreact": "TSX"},
+			"":       {"mylang": "My Language"},
+		},
+	}
+
+	tests := map[string]struct {
+		ID       string
+		Editor   string
+		Expected string
+	}{
+		"vscode id": {
+			ID:       "javascriptreact",
+			Editor:   "vscode",
+			Expected: "JavaScript",
+		},
+		"vscode shellscript": {
+			ID:       "shellscript",
+			Editor:   "vscode",
+			Expected: "Bash",
+		},
+		"jetbrains id": {
+			ID:       "TypeScript JSX",
+			Editor:   "jetbrains",
+			Expected: "TypeScript",
+		},
+		"config overrides builtin": {
+			ID:       "typescriptreact",
+			Editor:   "vscode",
+			Expected: "TSX",
+		},
+		"config for all editors": {
+			ID:       "mylang",
+			Editor:   "jetbrains",
+			Expected: "My Language",
+		},
+		"unknown id": {
+			ID:       "golang",
+			Editor:   "vscode",
+			Expected: "golang",
+		},
+		"case of known language": {
+			ID:       "python",
+			Editor:   "vscode",
+			Expected: "Python",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, language.Normalize(test.ID, test.Editor, config))
+		})
+	}
+}
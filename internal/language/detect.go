@@ -0,0 +1,258 @@
+package language
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+const (
+	// maxHeadTailBytes is the number of bytes read from the start and the end of
+	// a file to look for shebang lines and modelines.
+	maxHeadTailBytes = 4096
+	// modelineLines is the number of lines at the start and the end of a file,
+	// which may contain a modeline.
+	modelineLines = 5
+)
+
+var (
+	vimModelineRegex   = regexp.MustCompile(`(?:^|\s)(?:vi|vim|ex)(?:[<=>]?\d+)?:.*?\b(?:ft|filetype|syntax)=([\w+-]+)`)
+	emacsModelineRegex = regexp.MustCompile(`-\*-(.+?)-\*-`)
+)
+
+// Config holds user defined additions to the built-in language tables, which
+// take precedence over them.
+type Config struct {
+	// Extensions maps file extensions to languages.
+	Extensions map[string]string
+	// Filenames maps well-known filenames to languages.
+	Filenames map[string]string
+	// Aliases maps editor language ids to languages, keyed by editor family.
+	// Aliases under the empty key apply to all editors.
+	Aliases map[string]map[string]string
+}
+
+// WithDetection initializes and returns a heartbeat handle option, which
+// detects the language of file heartbeats sent without one.
+func WithDetection(config Config) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugln("Execute language detection")
+
+			for n, h := range hs {
+				if h.Language != nil || h.EntityType != heartbeat.FileType {
+					continue
+				}
+
+				var (
+					lang string
+					ok   bool
+				)
+
+				if h.IsLocalFile() {
+					lang, ok = detect(h.Entity, h.LocalFilepath(), config)
+				} else {
+					// remote files can't be read, so only their name is used
+					lang, ok = DetectFilename(h.Entity, config)
+				}
+
+				if !ok {
+					logger.Debugf("Failed to detect language of %s", h.Entity)
+					continue
+				}
+
+				hs[n].Language = &lang
+			}
+
+			return next(ctx, hs)
+		}
+	}
+}
+
+// Detect works out the language of the file at fp. Vim and Emacs modelines take
+// precedence over well-known filenames and extensions, and shebang lines are
+// used for files without either.
+func Detect(fp string, config Config) (string, bool) {
+	return detect(fp, fp, config)
+}
+
+// detect works out the language of the file named fp, whose content is read
+// from localFp.
+func detect(fp, localFp string, config Config) (string, bool) {
+	head, tail := readHeadTail(localFp)
+
+	if lang, ok := detectModeline(head, tail); ok {
+		return lang, true
+	}
+
+	if lang, ok := DetectFilename(fp, config); ok {
+		return lang, true
+	}
+
+	return detectShebang(head)
+}
+
+// DetectFilename works out the language of the file at fp from its well-known
+// filename or extension, without reading it.
+func DetectFilename(fp string, config Config) (string, bool) {
+	base := strings.ToLower(path.Base(filepath.ToSlash(fp)))
+
+	if lang, ok := lookupTable(config.Filenames, filenames, base); ok {
+		return lang, true
+	}
+
+	if ext := strings.TrimPrefix(path.Ext(base), "."); ext != "" {
+		if lang, ok := lookupTable(config.Extensions, extensions, ext); ok {
+			return lang, true
+		}
+	}
+
+	return "", false
+}
+
+func lookupTable(custom, builtin map[string]string, key string) (string, bool) {
+	for k, lang := range custom {
+		if strings.ToLower(strings.TrimPrefix(k, ".")) == key {
+			return lang, true
+		}
+	}
+
+	lang, ok := builtin[key]
+
+	return lang, ok
+}
+
+// readHeadTail returns the first and the last lines of the file at fp, within
+// maxHeadTailBytes each. Missing and unreadable files return nothing.
+func readHeadTail(fp string) (head, tail []string) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	buf := make([]byte, maxHeadTailBytes)
+
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, nil
+	}
+
+	head = splitLines(buf[:n])
+	if len(head) > modelineLines {
+		head = head[:modelineLines]
+	}
+
+	if info.Size() <= maxHeadTailBytes {
+		tail = splitLines(buf[:n])
+	} else {
+		n, err = f.ReadAt(buf, info.Size()-maxHeadTailBytes)
+		if err != nil && err != io.EOF {
+			return head, nil
+		}
+
+		tail = splitLines(buf[:n])
+	}
+
+	if len(tail) > modelineLines {
+		tail = tail[len(tail)-modelineLines:]
+	}
+
+	return head, tail
+}
+
+func splitLines(data []byte) []string {
+	var lines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	return lines
+}
+
+func detectModeline(head, tail []string) (string, bool) {
+	for _, line := range append(head, tail...) {
+		if match := vimModelineRegex.FindStringSubmatch(line); match != nil {
+			if lang, ok := Lookup(match[1]); ok {
+				return lang, true
+			}
+		}
+
+		if match := emacsModelineRegex.FindStringSubmatch(line); match != nil {
+			if lang, ok := Lookup(emacsMode(match[1])); ok {
+				return lang, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// emacsMode returns the major mode of an emacs modeline, which is either of the
+// form "-*- python -*-" or "-*- mode: python; coding: utf-8 -*-".
+func emacsMode(modeline string) string {
+	for _, variable := range strings.Split(modeline, ";") {
+		key, value, ok := strings.Cut(variable, ":")
+		if !ok {
+			if strings.TrimSpace(variable) != "" && !strings.Contains(modeline, ":") {
+				return strings.TrimSpace(variable)
+			}
+
+			continue
+		}
+
+		if strings.EqualFold(strings.TrimSpace(key), "mode") {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// detectShebang works out the language from the interpreter of a shebang line,
+// like "#!/bin/sh" or "#!/usr/bin/env -S python3 -u".
+func detectShebang(head []string) (string, bool) {
+	if len(head) == 0 || !strings.HasPrefix(head[0], "#!") {
+		return "", false
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(head[0], "#!"))
+	if len(fields) == 0 {
+		return "", false
+	}
+
+	interpreter := filepath.Base(fields[0])
+
+	if interpreter == "env" {
+		interpreter = ""
+
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = filepath.Base(field)
+				break
+			}
+		}
+	}
+
+	interpreter = strings.ToLower(strings.TrimRight(interpreter, "0123456789."))
+	lang, ok := interpreters[interpreter]
+
+	return lang, ok
+}
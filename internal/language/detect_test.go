@@ -0,0 +1,67 @@
+package language_test
+
+import (
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/language"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	config := language.Config{
+		Extensions: map[string]string{"tpl": "Go Template"},
+	}
+
+	tests := map[string]struct {
+		Filepath string
+		Expected string
+	}{
+		"extension": {
+			Filepath: "testdata/main.go",
+			Expected: "Go",
+		},
+		"custom extension": {
+			Filepath: "testdata/template.tpl",
+			Expected: "Go Template",
+		},
+		"makefile": {
+			Filepath: "testdata/Makefile",
+			Expected: "Makefile",
+		},
+		"dockerfile": {
+			Filepath: "testdata/Dockerfile",
+			Expected: "Docker",
+		},
+		"shebang": {
+			Filepath: "testdata/script",
+			Expected: "Python",
+		},
+		"vim modeline": {
+			Filepath: "testdata/modeline_vim.txt",
+			Expected: "Ruby",
+		},
+		"emacs modeline": {
+			Filepath: "testdata/modeline_emacs",
+			Expected: "Bash",
+		},
+		"missing file with extension": {
+			Filepath: "testdata/missing.rs",
+			Expected: "Rust",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lang, ok := language.Detect(test.Filepath, config)
+			require.True(t, ok)
+
+			assert.Equal(t, test.Expected, lang)
+		})
+	}
+}
+
+func TestDetect_Unknown(t *testing.T) {
+	_, ok := language.Detect("testdata/missing", language.Config{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,200 @@
+package language
+
+import "strings"
+
+// extensions maps lower case file extensions, without the leading dot, to languages.
+var extensions = map[string]string{
+	"asm":        "Assembly",
+	"bash":       "Bash",
+	"bat":        "Batchfile",
+	"c":          "C",
+	"cc":         "C++",
+	"cjs":        "JavaScript",
+	"clj":        "Clojure",
+	"cljs":       "ClojureScript",
+	"cmake":      "CMake",
+	"cmd":        "Batchfile",
+	"cpp":        "C++",
+	"cs":         "C#",
+	"css":        "CSS",
+	"csv":        "CSV",
+	"cts":        "TypeScript",
+	"cxx":        "C++",
+	"dart":       "Dart",
+	"diff":       "Diff",
+	"dockerfile": "Docker",
+	"elm":        "Elm",
+	"erl":        "Erlang",
+	"ex":         "Elixir",
+	"exs":        "Elixir",
+	"fish":       "Fish",
+	"fs":         "F#",
+	"go":         "Go",
+	"gradle":     "Gradle",
+	"graphql":    "GraphQL",
+	"groovy":     "Groovy",
+	"h":          "C",
+	"hcl":        "HCL",
+	"hpp":        "C++",
+	"hs":         "Haskell",
+	"htm":        "HTML",
+	"html":       "HTML",
+	"hxx":        "C++",
+	"ini":        "INI",
+	"ipynb":      "Jupyter",
+	"java":       "Java",
+	"jl":         "Julia",
+	"js":         "JavaScript",
+	"json":       "JSON",
+	"jsonc":      "JSON",
+	"jsx":        "JavaScript",
+	"kt":         "Kotlin",
+	"kts":        "Kotlin",
+	"less":       "LESS",
+	"lua":        "Lua",
+	"m":          "Objective-C",
+	"md":         "Markdown",
+	"mdx":        "MDX",
+	"mjs":        "JavaScript",
+	"ml":         "OCaml",
+	"mm":         "Objective-C++",
+	"mts":        "TypeScript",
+	"nim":        "Nim",
+	"nix":        "Nix",
+	"php":        "PHP",
+	"pl":         "Perl",
+	"pm":         "Perl",
+	"proto":      "Protocol Buffer",
+	"ps1":        "PowerShell",
+	"py":         "Python",
+	"pyi":        "Python",
+	"r":          "R",
+	"rb":         "Ruby",
+	"rs":         "Rust",
+	"rst":        "reStructuredText",
+	"sass":       "Sass",
+	"scala":      "Scala",
+	"scss":       "SCSS",
+	"sh":         "Bash",
+	"sol":        "Solidity",
+	"sql":        "SQL",
+	"svelte":     "Svelte",
+	"svg":        "SVG",
+	"swift":      "Swift",
+	"tex":        "TeX",
+	"tf":         "HCL",
+	"toml":       "TOML",
+	"ts":         "TypeScript",
+	"tsx":        "TypeScript",
+	"txt":        "Text",
+	"vim":        "VimL",
+	"vue":        "Vue.js",
+	"xml":        "XML",
+	"yaml":       "YAML",
+	"yml":        "YAML",
+	"zig":        "Zig",
+	"zsh":        "Bash",
+}
+
+// filenames maps lower case well-known filenames to languages.
+var filenames = map[string]string{
+	".bashrc":          "Bash",
+	".gitignore":       "Git Config",
+	".vimrc":           "VimL",
+	".zshrc":           "Bash",
+	"cmakelists.txt":   "CMake",
+	"containerfile":    "Docker",
+	"dockerfile":       "Docker",
+	"gemfile":          "Ruby",
+	"gnumakefile":      "Makefile",
+	"go.mod":           "Go",
+	"go.sum":           "Go",
+	"jenkinsfile":      "Groovy",
+	"justfile":         "Just",
+	"makefile":         "Makefile",
+	"package.json":     "JSON",
+	"rakefile":         "Ruby",
+	"requirements.txt": "Text",
+	"vagrantfile":      "Ruby",
+}
+
+// interpreters maps shebang interpreters, without version suffix, to languages.
+var interpreters = map[string]string{
+	"ash":     "Bash",
+	"bash":    "Bash",
+	"bun":     "JavaScript",
+	"dash":    "Bash",
+	"deno":    "TypeScript",
+	"fish":    "Fish",
+	"ksh":     "Bash",
+	"lua":     "Lua",
+	"node":    "JavaScript",
+	"nodejs":  "JavaScript",
+	"perl":    "Perl",
+	"php":     "PHP",
+	"pwsh":    "PowerShell",
+	"python":  "Python",
+	"rscript": "R",
+	"ruby":    "Ruby",
+	"sh":      "Bash",
+	"ts-node": "TypeScript",
+	"tsx":     "TypeScript",
+	"zsh":     "Bash",
+}
+
+// modes maps vim filetypes and emacs major modes to languages, besides the
+// lower case language names themselves.
+var modes = map[string]string{
+	"c++":             "C++",
+	"cpp":             "C++",
+	"cs":              "C#",
+	"csharp":          "C#",
+	"dockerfile":      "Docker",
+	"js":              "JavaScript",
+	"js2":             "JavaScript",
+	"make":            "Makefile",
+	"md":              "Markdown",
+	"objc":            "Objective-C",
+	"perl6":           "Raku",
+	"ps1":             "PowerShell",
+	"py":              "Python",
+	"python3":         "Python",
+	"rb":              "Ruby",
+	"rs":              "Rust",
+	"sh":              "Bash",
+	"shell":           "Bash",
+	"shell-script":    "Bash",
+	"ts":              "TypeScript",
+	"typescriptreact": "TypeScript",
+	"javascriptreact": "JavaScript",
+	"vim":             "VimL",
+	"yml":             "YAML",
+	"zsh":             "Bash",
+}
+
+// byName maps lower case language names to their canonical spelling.
+var byName = func() map[string]string {
+	names := map[string]string{}
+
+	for _, tables := range []map[string]string{extensions, filenames, interpreters, modes} {
+		for _, lang := range tables {
+			names[strings.ToLower(lang)] = lang
+		}
+	}
+
+	return names
+}()
+
+// Lookup returns the canonical language for a language name, a vim filetype or
+// an emacs mode, ignoring case.
+func Lookup(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if lang, ok := modes[name]; ok {
+		return lang, true
+	}
+
+	lang, ok := byName[name]
+
+	return lang, ok
+}
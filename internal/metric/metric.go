@@ -21,6 +21,23 @@ type (
 		Ratios     []MetricRatio[T]   `json:"ratios"`
 		Metric     string             `json:"metric"`
 	}
+
+	// MetricChurn holds the lines added and removed in the files of one value,
+	// like a project.
+	MetricChurn struct {
+		Value        string `json:"value"`
+		LinesAdded   uint64 `json:"linesAdded"`
+		LinesRemoved uint64 `json:"linesRemoved"`
+	}
+
+	// MetricChurnData holds the churn of each value, along with the lines added
+	// and removed in total.
+	MetricChurnData struct {
+		Churns       []MetricChurn `json:"churns"`
+		LinesAdded   uint64        `json:"linesAdded"`
+		LinesRemoved uint64        `json:"linesRemoved"`
+		Metric       string        `json:"metric"`
+	}
 )
 
 // custom MarshalJSON
@@ -6,9 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net/http"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/result17/codeBeatCli/internal/api"
 	"github.com/result17/codeBeatCli/internal/heartbeat"
 	"github.com/result17/codeBeatCli/internal/workspace"
 	"github.com/result17/codeBeatCli/pkg/log"
@@ -22,6 +26,9 @@ const (
 	// maxRequeueAttempts defines the maximum number of attempts to requeue heartbeats,
 	// which could not successfully be sent to the WakaTime API.
 	maxRequeueAttempts = 3
+	// localBucket keeps heartbeats saved with --local-save apart from the ones
+	// waiting to be synced.
+	localBucket = "local_heartbeats"
 )
 
 func openDB(ctx context.Context, fp string) (db *bolt.DB, _ func(), err error) {
@@ -32,6 +39,9 @@ func openDB(ctx context.Context, fp string) (db *bolt.DB, _ func(), err error) {
 	}()
 	logger := log.Extract(ctx)
 	logger.Debugf("Open db file: %s", fp)
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create db directory: %s", err)
+	}
 	db, err = bolt.Open(fp, 0644, &bolt.Options{Timeout: 30 * time.Second})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to open db file: %s", err)
@@ -74,10 +84,15 @@ func WithQueue(fp string) heartbeat.HandleOption {
 				return nil, nil
 			}
 			results, err := next(ctx, hs)
+			if errors.Is(err, api.ErrBadRequest) {
+				logger.Warnf("Dropping %d heartbeat(s) rejected by api: %s", len(hs), err)
+				return nil, err
+			}
+
 			if err != nil {
 				logger.Debugf("Pushing %d heartbeat(s) to queue after error: %s", len(hs), err)
 
-				requeueErr := pushHeartbeatsWithRetry(ctx, fp, hs)
+				requeueErr := pushHeartbeatsWithRetry(ctx, fp, dbBucket, hs)
 				if requeueErr != nil {
 					return nil, fmt.Errorf(
 						"Failed to push heartbeats to queue: %s",
@@ -98,7 +113,7 @@ func WithQueue(fp string) heartbeat.HandleOption {
 func SaveHeartbeat(fp string) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
-			requeueErr := pushHeartbeatsWithRetry(ctx, fp, hs)
+			requeueErr := pushHeartbeatsWithRetry(ctx, fp, localBucket, hs)
 			if requeueErr != nil {
 				return nil, fmt.Errorf(
 					"saving heartbeat locally failed to push heartbeats to queue: %s",
@@ -111,7 +126,7 @@ func SaveHeartbeat(fp string) heartbeat.HandleOption {
 	}
 }
 
-func pushHeartbeatsWithRetry(ctx context.Context, fp string, hs []heartbeat.Heartbeat) error {
+func pushHeartbeatsWithRetry(ctx context.Context, fp string, bucket string, hs []heartbeat.Heartbeat) error {
 	var (
 		count int
 		err   error
@@ -133,7 +148,7 @@ func pushHeartbeatsWithRetry(ctx context.Context, fp string, hs []heartbeat.Hear
 				string(serialized),
 			)
 		}
-		err = pushHeartbeats(ctx, fp, hs)
+		err = pushHeartbeats(ctx, fp, bucket, hs)
 		if err != nil {
 			count++
 			sleepSeconds := math.Pow(2, float64(count))
@@ -147,7 +162,7 @@ func pushHeartbeatsWithRetry(ctx context.Context, fp string, hs []heartbeat.Hear
 	return nil
 }
 
-func pushHeartbeats(ctx context.Context, fp string, hs []heartbeat.Heartbeat) error {
+func pushHeartbeats(ctx context.Context, fp string, bucket string, hs []heartbeat.Heartbeat) error {
 	db, close, err := openDB(ctx, fp)
 	if err != nil {
 		return err
@@ -160,8 +175,10 @@ func pushHeartbeats(ctx context.Context, fp string, hs []heartbeat.Heartbeat) er
 	}
 
 	queue := NewQueue(tx)
+	queue.Bucket = bucket
 	err = queue.PushMany(hs)
 	if err != nil {
+		_ = tx.Rollback()
 		return fmt.Errorf("failed to push heartbeat(s) to queue: %s", err)
 	}
 
@@ -171,7 +188,41 @@ func pushHeartbeats(ctx context.Context, fp string, hs []heartbeat.Heartbeat) er
 	return nil
 }
 
-// TODO handle API response
+// handleResults pairs each api result with the heartbeat it was sent for.
+// Heartbeats rejected with a 4xx status are dropped, while the ones failed with
+// a 5xx or 429 status, or left without a result, are pushed back to the queue.
 func handleResults(ctx context.Context, fp string, results []heartbeat.Result, hs []heartbeat.Heartbeat) error {
-	return nil
+	logger := log.Extract(ctx)
+
+	var requeue []heartbeat.Heartbeat
+
+	for n, h := range hs {
+		if n >= len(results) {
+			logger.Debugf("Missing api result for heartbeat %s", h.ID())
+			requeue = append(requeue, h)
+
+			continue
+		}
+
+		result := results[n]
+
+		switch {
+		case result.Status == http.StatusTooManyRequests || result.Status >= http.StatusInternalServerError:
+			logger.Debugf("Requeue heartbeat %s after api status %d", h.ID(), result.Status)
+			requeue = append(requeue, h)
+		case result.Status >= http.StatusBadRequest:
+			logger.Warnf(
+				"Dropping heartbeat %s rejected by api with status %d: %s",
+				h.ID(),
+				result.Status,
+				strings.Join(result.Errors, " "),
+			)
+		}
+	}
+
+	if len(requeue) == 0 {
+		return nil
+	}
+
+	return pushHeartbeatsWithRetry(ctx, fp, dbBucket, requeue)
 }
@@ -0,0 +1,43 @@
+package offline_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/api"
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/offline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithQueue(t *testing.T) {
+	tests := map[string]struct {
+		Err      error
+		Expected int
+	}{
+		"requeue after connection error": {Err: errors.New("connection refused"), Expected: 2},
+		"drop after bad request":         {Err: fmt.Errorf("%w at %q", api.ErrBadRequest, "http://localhost"), Expected: 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), "offline.bdb")
+
+			handle := offline.WithQueue(fp)(func(_ context.Context, _ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+				return nil, test.Err
+			})
+
+			_, err := handle(t.Context(), []heartbeat.Heartbeat{
+				{Entity: "/tmp/main.go", Time: 1585598059100},
+				{Entity: "/tmp/util.go", Time: 1585598059200},
+			})
+			require.ErrorIs(t, err, test.Err)
+
+			assert.Equal(t, test.Expected, countTestHeartbeats(t, fp))
+		})
+	}
+}
@@ -36,7 +36,7 @@ func (q *Queue) checkBucketExist() (*bolt.Bucket, error) {
 
 func (q *Queue) checkBucketExistIfNotCreate() (*bolt.Bucket, error) {
 	bucket, _ := q.checkBucketExist()
-	if bucket != nil {
+	if bucket == nil {
 		bucket, err := q.tx.CreateBucket([]byte(q.Bucket))
 		if err != nil {
 			return nil, fmt.Errorf("failed to create bucket: %s", err)
@@ -0,0 +1,42 @@
+package offline_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/offline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestQueue_KeepsHostname(t *testing.T) {
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "offline.bdb"), 0644, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	hostname := "dev-laptop"
+	h := heartbeat.Heartbeat{
+		Entity:    "/tmp/main.go",
+		Hostname:  &hostname,
+		Time:      1585598059100,
+		UserAgent: "codeBeat/unset",
+	}
+
+	var queued []heartbeat.Heartbeat
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		queue := offline.NewQueue(tx)
+		if err := queue.PushMany([]heartbeat.Heartbeat{h}); err != nil {
+			return err
+		}
+
+		queued, err = queue.PopMany(1)
+
+		return err
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Heartbeat{h}, queued)
+}
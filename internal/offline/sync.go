@@ -0,0 +1,127 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/result17/codeBeatCli/internal/api"
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+// SendLimit is the maximum number of heartbeats sent to the api in one request.
+const SendLimit = 25
+
+// Sync pops heartbeats from the offline queue in batches of at most SendLimit
+// and sends them via sender, until syncLimit heartbeats have been processed.
+// A syncLimit of 0 drains the whole queue. A batch rejected by the api with a
+// 4xx status is dropped, as it would be rejected again. A batch failing with
+// any other error is pushed back to the queue, and the sync stops. It returns
+// the number of processed heartbeats.
+func Sync(ctx context.Context, fp string, syncLimit int, sender heartbeat.Sender) (int, error) {
+	logger := log.Extract(ctx)
+
+	total, err := countHeartbeats(ctx, fp)
+	if err != nil {
+		return 0, err
+	}
+
+	if syncLimit > 0 && syncLimit < total {
+		total = syncLimit
+	}
+
+	logger.Debugf("Syncing %d offline heartbeat(s) from %s", total, fp)
+
+	var synced int
+
+	for synced < total {
+		hs, err := popHeartbeats(ctx, fp, min(SendLimit, total-synced))
+		if err != nil {
+			return synced, err
+		}
+
+		if len(hs) == 0 {
+			break
+		}
+
+		results, err := sender.SendHeartbeats(ctx, hs)
+		if errors.Is(err, api.ErrBadRequest) {
+			logger.Warnf("Dropping %d offline heartbeat(s) rejected by api: %s", len(hs), err)
+
+			synced += len(hs)
+
+			continue
+		}
+
+		if err != nil {
+			logger.Debugf("Pushing %d heartbeat(s) back to queue after error: %s", len(hs), err)
+
+			requeueErr := pushHeartbeatsWithRetry(ctx, fp, dbBucket, hs)
+			if requeueErr != nil {
+				return synced, fmt.Errorf("Failed to push heartbeats to queue: %s", requeueErr)
+			}
+
+			return synced, fmt.Errorf("Failed to send offline heartbeats: %w", err)
+		}
+
+		err = handleResults(ctx, fp, results, hs)
+		if err != nil {
+			return synced, fmt.Errorf("Fail to handle results: %s", err)
+		}
+
+		synced += len(hs)
+	}
+
+	return synced, nil
+}
+
+func countHeartbeats(ctx context.Context, fp string) (int, error) {
+	db, close, err := openDB(ctx, fp)
+	if err != nil {
+		return 0, err
+	}
+	defer close()
+
+	tx, err := db.Begin(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start db transaction: %s", err)
+	}
+
+	count, err := NewQueue(tx).Count()
+	if err != nil {
+		_ = tx.Rollback()
+		return 0, fmt.Errorf("failed to count queued heartbeats: %s", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit db transaction: %s", err)
+	}
+
+	return count, nil
+}
+
+func popHeartbeats(ctx context.Context, fp string, limit int) ([]heartbeat.Heartbeat, error) {
+	db, close, err := openDB(ctx, fp)
+	if err != nil {
+		return nil, err
+	}
+	defer close()
+
+	tx, err := db.Begin(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start db transaction: %s", err)
+	}
+
+	hs, err := NewQueue(tx).PopMany(limit)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("failed to pop heartbeat(s) from queue: %s", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit db transaction: %s", err)
+	}
+
+	return hs, nil
+}
@@ -0,0 +1,156 @@
+package offline_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/api"
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/offline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+type senderFunc func(context.Context, []heartbeat.Heartbeat) ([]heartbeat.Result, error)
+
+func (f senderFunc) SendHeartbeats(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	return f(ctx, hs)
+}
+
+func pushTestHeartbeats(t *testing.T, fp string, n int) {
+	db, err := bolt.Open(fp, 0644, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var hs []heartbeat.Heartbeat
+	for i := range n {
+		hs = append(hs, heartbeat.Heartbeat{
+			Entity:    "/tmp/main.go",
+			Time:      uint64(1585598059100 + i),
+			UserAgent: "codeBeat/unset",
+		})
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		return offline.NewQueue(tx).PushMany(hs)
+	})
+	require.NoError(t, err)
+}
+
+func countTestHeartbeats(t *testing.T, fp string) int {
+	db, err := bolt.Open(fp, 0644, nil)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var count int
+	err = db.Update(func(tx *bolt.Tx) error {
+		count, err = offline.NewQueue(tx).Count()
+		return err
+	})
+	require.NoError(t, err)
+
+	return count
+}
+
+func TestSync(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "offline.bdb")
+	pushTestHeartbeats(t, fp, 30)
+
+	var batches []int
+	sender := senderFunc(func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		batches = append(batches, len(hs))
+		results := make([]heartbeat.Result, len(hs))
+		for n, h := range hs {
+			results[n] = heartbeat.Result{Status: 201, Heartbeat: h}
+		}
+		return results, nil
+	})
+
+	synced, err := offline.Sync(t.Context(), fp, 0, sender)
+	require.NoError(t, err)
+
+	assert.Equal(t, 30, synced)
+	assert.Equal(t, []int{offline.SendLimit, 5}, batches)
+	assert.Zero(t, countTestHeartbeats(t, fp))
+}
+
+func TestSyncWithLimit(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "offline.bdb")
+	pushTestHeartbeats(t, fp, 10)
+
+	sender := senderFunc(func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return make([]heartbeat.Result, len(hs)), nil
+	})
+
+	synced, err := offline.Sync(t.Context(), fp, 4, sender)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, synced)
+	assert.Equal(t, 6, countTestHeartbeats(t, fp))
+}
+
+func TestSyncRequeuesOnError(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "offline.bdb")
+	pushTestHeartbeats(t, fp, 3)
+
+	sender := senderFunc(func(_ context.Context, _ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	synced, err := offline.Sync(t.Context(), fp, 0, sender)
+	require.Error(t, err)
+
+	assert.Zero(t, synced)
+	assert.Equal(t, 3, countTestHeartbeats(t, fp))
+}
+
+func TestSyncDropsBadRequest(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "offline.bdb")
+	pushTestHeartbeats(t, fp, 30)
+
+	var batches int
+	sender := senderFunc(func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		batches++
+		if batches == 1 {
+			return nil, fmt.Errorf("%w at %q", api.ErrBadRequest, "http://localhost")
+		}
+
+		results := make([]heartbeat.Result, len(hs))
+		for n, h := range hs {
+			results[n] = heartbeat.Result{Status: 201, Heartbeat: h}
+		}
+		return results, nil
+	})
+
+	synced, err := offline.Sync(t.Context(), fp, 0, sender)
+	require.NoError(t, err)
+
+	// the rejected first batch doesn't block the following one
+	assert.Equal(t, 30, synced)
+	assert.Equal(t, 2, batches)
+	assert.Zero(t, countTestHeartbeats(t, fp))
+}
+
+func TestSyncHandlesResultStatus(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "offline.bdb")
+	pushTestHeartbeats(t, fp, 4)
+
+	sender := senderFunc(func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return []heartbeat.Result{
+			{Status: 201, Heartbeat: hs[0]},
+			{Status: 400, Heartbeat: hs[1], Errors: []string{"invalid entity"}},
+			{Status: 503, Heartbeat: hs[2]},
+		}, nil
+	})
+
+	synced, err := offline.Sync(t.Context(), fp, 0, sender)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, synced)
+	// the 503 heartbeat and the one without a result are requeued
+	assert.Equal(t, 2, countTestHeartbeats(t, fp))
+}
@@ -0,0 +1,16 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package platform
+
+import "syscall"
+
+// detectKernel returns the kernel release, read by sysctl instead of running
+// uname.
+func detectKernel() string {
+	release, err := syscall.Sysctl("kern.osrelease")
+	if err != nil {
+		return ""
+	}
+
+	return release
+}
@@ -0,0 +1,17 @@
+package platform
+
+import (
+	"os"
+	"strings"
+)
+
+// detectKernel returns the kernel release, read from procfs instead of running
+// uname.
+func detectKernel() string {
+	data, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !windows && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package platform
+
+// detectKernel returns nothing on platforms, whose kernel release is unknown.
+func detectKernel() string {
+	return ""
+}
@@ -0,0 +1,15 @@
+package platform
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// detectKernel returns the windows version, like "10.0.22631". Unlike
+// GetVersionEx, RtlGetVersion is not affected by compatibility shims.
+func detectKernel() string {
+	v := windows.RtlGetVersion()
+
+	return fmt.Sprintf("%d.%d.%d", v.MajorVersion, v.MinorVersion, v.BuildNumber)
+}
@@ -0,0 +1,263 @@
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/result17/codeBeatCli/internal/statefile"
+	"github.com/result17/codeBeatCli/internal/windows"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+const (
+	// CacheFilename is the filename of the cached platform info, kept next to
+	// the other state files of the offline queue.
+	CacheFilename = "platform_cache_codebeat.json"
+	// cacheTTL is how long the cached platform info is used, before it is
+	// detected again. Kernels and distros only change on upgrades.
+	cacheTTL = 24 * time.Hour
+)
+
+var (
+	// ciEnvVars are set by continuous integration services.
+	ciEnvVars = []string{
+		"CI",
+		"BUILDKITE",
+		"CIRCLECI",
+		"GITHUB_ACTIONS",
+		"GITLAB_CI",
+		"JENKINS_URL",
+		"TEAMCITY_VERSION",
+		"TF_BUILD",
+		"TRAVIS",
+	}
+	// containerCgroupRegex matches cgroups of processes run by container engines.
+	containerCgroupRegex = regexp.MustCompile(`(?m)(?:docker|kubepods|containerd|libpod|lxc)`)
+	// plistVersionRegex matches the ProductVersion key of macOS SystemVersion.plist.
+	plistVersionRegex = regexp.MustCompile(`<key>ProductVersion</key>\s*<string>([^<]+)</string>`)
+)
+
+// Info describes the platform the CLI runs on.
+type Info struct {
+	// OS is the operating system, as reported by runtime.GOOS.
+	OS string `json:"os"`
+	// Arch is the architecture, as reported by runtime.GOARCH.
+	Arch string `json:"arch"`
+	// Kernel is the kernel release, like "6.8.0-45-generic".
+	Kernel string `json:"kernel,omitempty"`
+	// Distro is the name and version of the linux distribution, or of macOS.
+	Distro string `json:"distro,omitempty"`
+	// WSL is set when running inside the Windows Subsystem for Linux.
+	WSL bool `json:"wsl,omitempty"`
+	// Container is set when running inside a container.
+	Container bool `json:"container,omitempty"`
+	// CI is set when running on a continuous integration service. It depends
+	// on the environment of each invocation, so it is never cached.
+	CI bool `json:"-"`
+}
+
+// String formats i for the user agent, like
+// "linux-6.8.0-45-generic-amd64; Ubuntu 24.04 LTS; wsl".
+func (i Info) String() string {
+	parts := []string{i.OS}
+	if i.Kernel != "" {
+		parts = append(parts, i.Kernel)
+	}
+
+	parts = append(parts, i.Arch)
+
+	s := strings.Join(parts, "-")
+
+	if i.Distro != "" {
+		s += "; " + i.Distro
+	}
+
+	for _, flag := range []struct {
+		Name string
+		Set  bool
+	}{
+		{"wsl", i.WSL},
+		{"container", i.Container},
+		{"ci", i.CI},
+	} {
+		if flag.Set {
+			s += "; " + flag.Name
+		}
+	}
+
+	return s
+}
+
+type cache struct {
+	Info       Info      `json:"info"`
+	Hostname   string    `json:"hostname"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// Load returns the platform info cached in the file at cacheFilepath, if it
+// was detected within cacheTTL on the same machine. Otherwise the platform is
+// detected again and cached. Caching failures are logged, but never fail.
+func Load(ctx context.Context, cacheFilepath string) Info {
+	logger := log.Extract(ctx)
+
+	hostname, _ := os.Hostname()
+
+	c, err := readCache(cacheFilepath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Debugf("Failed to read platform cache: %s", err)
+	}
+
+	if err == nil && c.isValid(hostname, time.Now()) {
+		c.Info.CI = DetectCI(os.Getenv)
+		return c.Info
+	}
+
+	info := Detect()
+
+	c = cache{Info: info, Hostname: hostname, DetectedAt: time.Now()}
+	if err := writeCache(cacheFilepath, c); err != nil {
+		logger.Debugf("Failed to write platform cache: %s", err)
+	}
+
+	return info
+}
+
+// Detect returns the info of the current platform, without using the cache.
+// Only files are read, so no commands are run.
+func Detect() Info {
+	info := Info{
+		OS:     runtime.GOOS,
+		Arch:   runtime.GOARCH,
+		Kernel: detectKernel(),
+		Distro: detectDistro(),
+		CI:     DetectCI(os.Getenv),
+	}
+
+	if runtime.GOOS == "linux" {
+		info.WSL = windows.IsWSL()
+		info.Container = detectContainer()
+	}
+
+	return info
+}
+
+// DetectCI reports whether any of the environment variables set by continuous
+// integration services is set, using getenv to look them up.
+func DetectCI(getenv func(string) string) bool {
+	for _, name := range ciEnvVars {
+		value := strings.ToLower(strings.TrimSpace(getenv(name)))
+		if value != "" && value != "false" && value != "0" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseOSRelease returns the distro described by an os-release file, see
+// os-release(5). PRETTY_NAME is preferred over NAME and VERSION_ID.
+func ParseOSRelease(data []byte) string {
+	values := map[string]string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || strings.HasPrefix(key, "#") {
+			continue
+		}
+
+		values[key] = strings.Trim(strings.TrimSpace(value), `"'`)
+	}
+
+	if pretty := values["PRETTY_NAME"]; pretty != "" {
+		return pretty
+	}
+
+	return strings.TrimSpace(values["NAME"] + " " + values["VERSION_ID"])
+}
+
+// ParseSystemVersion returns the macOS version described by the content of
+// /System/Library/CoreServices/SystemVersion.plist.
+func ParseSystemVersion(data []byte) string {
+	m := plistVersionRegex.FindSubmatch(data)
+	if m == nil {
+		return ""
+	}
+
+	return "macOS " + strings.TrimSpace(string(m[1]))
+}
+
+// IsContainerCgroup reports whether the content of /proc/1/cgroup belongs to a
+// process started by a container engine.
+func IsContainerCgroup(data []byte) bool {
+	return containerCgroupRegex.Match(data)
+}
+
+func detectDistro() string {
+	switch runtime.GOOS {
+	case "linux":
+		for _, fp := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+			if data, err := os.ReadFile(fp); err == nil {
+				return ParseOSRelease(data)
+			}
+		}
+	case "darwin":
+		if data, err := os.ReadFile("/System/Library/CoreServices/SystemVersion.plist"); err == nil {
+			return ParseSystemVersion(data)
+		}
+	}
+
+	return ""
+}
+
+func detectContainer() bool {
+	if os.Getenv("container") != "" || os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+
+	for _, fp := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(fp); err == nil {
+			return true
+		}
+	}
+
+	data, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+
+	return IsContainerCgroup(data)
+}
+
+func (c cache) isValid(hostname string, now time.Time) bool {
+	return c.Info.OS == runtime.GOOS &&
+		c.Info.Arch == runtime.GOARCH &&
+		c.Hostname == hostname &&
+		now.Sub(c.DetectedAt) >= 0 &&
+		now.Sub(c.DetectedAt) < cacheTTL
+}
+
+func readCache(fp string) (cache, error) {
+	var c cache
+	if err := statefile.Read(fp, &c); err != nil {
+		return cache{}, err
+	}
+
+	return c, nil
+}
+
+func writeCache(fp string, c cache) error {
+	if err := statefile.Write(fp, c); err != nil {
+		return fmt.Errorf("failed to write platform cache: %s", err)
+	}
+
+	return nil
+}
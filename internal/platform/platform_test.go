@@ -0,0 +1,156 @@
+package platform_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/result17/codeBeatCli/internal/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfo_String(t *testing.T) {
+	tests := map[string]struct {
+		Info     platform.Info
+		Expected string
+	}{
+		"minimal": {
+			Info:     platform.Info{OS: "plan9", Arch: "amd64"},
+			Expected: "plan9-amd64",
+		},
+		"linux": {
+			Info:     platform.Info{OS: "linux", Arch: "amd64", Kernel: "6.8.0-45-generic", Distro: "Ubuntu 24.04 LTS"},
+			Expected: "linux-6.8.0-45-generic-amd64; Ubuntu 24.04 LTS",
+		},
+		"wsl container ci": {
+			Info: platform.Info{
+				OS:        "linux",
+				Arch:      "arm64",
+				Kernel:    "5.15.153.1-microsoft-standard-WSL2",
+				WSL:       true,
+				Container: true,
+				CI:        true,
+			},
+			Expected: "linux-5.15.153.1-microsoft-standard-WSL2-arm64; wsl; container; ci",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Info.String())
+		})
+	}
+}
+
+func TestDetectCI(t *testing.T) {
+	tests := map[string]struct {
+		Env      map[string]string
+		Expected bool
+	}{
+		"none":           {Env: map[string]string{}},
+		"ci":             {Env: map[string]string{"CI": "true"}, Expected: true},
+		"ci false":       {Env: map[string]string{"CI": "false"}},
+		"github actions": {Env: map[string]string{"GITHUB_ACTIONS": "true"}, Expected: true},
+		"jenkins":        {Env: map[string]string{"JENKINS_URL": "https://ci.example.com/"}, Expected: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			getenv := func(name string) string { return test.Env[name] }
+
+			assert.Equal(t, test.Expected, platform.DetectCI(getenv))
+		})
+	}
+}
+
+func TestParseOSRelease(t *testing.T) {
+	ubuntu := `NAME="Ubuntu"
+VERSION_ID="24.04"
+# comment
+PRETTY_NAME="Ubuntu 24.04 LTS"
+ID=ubuntu
+`
+	assert.Equal(t, "Ubuntu 24.04 LTS", platform.ParseOSRelease([]byte(ubuntu)))
+
+	alpine := "NAME='Alpine Linux'\nVERSION_ID=3.20.0\n"
+	assert.Equal(t, "Alpine Linux 3.20.0", platform.ParseOSRelease([]byte(alpine)))
+
+	assert.Empty(t, platform.ParseOSRelease(nil))
+}
+
+func TestParseSystemVersion(t *testing.T) {
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>ProductName</key>
+	<string>macOS</string>
+	<key>ProductVersion</key>
+	<string>14.5</string>
+</dict>
+</plist>`
+
+	assert.Equal(t, "macOS 14.5", platform.ParseSystemVersion([]byte(plist)))
+	assert.Empty(t, platform.ParseSystemVersion([]byte("<plist/>")))
+}
+
+func TestIsContainerCgroup(t *testing.T) {
+	assert.True(t, platform.IsContainerCgroup([]byte("12:pids:/docker/3f2a9c\n")))
+	assert.True(t, platform.IsContainerCgroup([]byte("0::/kubepods/besteffort/pod1234\n")))
+	assert.False(t, platform.IsContainerCgroup([]byte("0::/init.scope\n")))
+}
+
+func TestLoad(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err)
+
+	cached := platform.Info{OS: runtime.GOOS, Arch: runtime.GOARCH, Kernel: "cached-kernel"}
+
+	tests := map[string]struct {
+		Hostname   string
+		DetectedAt time.Time
+		Cached     bool
+	}{
+		"fresh":         {Hostname: hostname, DetectedAt: time.Now().Add(-time.Hour), Cached: true},
+		"expired":       {Hostname: hostname, DetectedAt: time.Now().Add(-48 * time.Hour)},
+		"other machine": {Hostname: hostname + "-other", DetectedAt: time.Now()},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), platform.CacheFilename)
+
+			data, err := json.Marshal(map[string]any{
+				"info":       cached,
+				"hostname":   test.Hostname,
+				"detectedAt": test.DetectedAt,
+			})
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(fp, data, 0644))
+
+			info := platform.Load(t.Context(), fp)
+
+			if test.Cached {
+				assert.Equal(t, "cached-kernel", info.Kernel)
+				return
+			}
+
+			assert.Equal(t, platform.Detect(), info)
+
+			// the detected info replaces the outdated cache
+			assert.Equal(t, info, platform.Load(t.Context(), fp))
+		})
+	}
+}
+
+func TestLoad_MissingCache(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), ".codebeat", platform.CacheFilename)
+
+	info := platform.Load(t.Context(), fp)
+	assert.Equal(t, runtime.GOOS, info.OS)
+	assert.Equal(t, runtime.GOARCH, info.Arch)
+	assert.FileExists(t, fp)
+}
@@ -0,0 +1,196 @@
+package privacy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+const (
+	// SaltFilename is the default filename of the generated salt, used when no
+	// salt is configured.
+	SaltFilename = "privacy_salt_codebeat"
+	// hashLength is the number of hex characters kept of hashed names.
+	hashLength = 16
+	// saltLength is the number of random bytes of a generated salt.
+	saltLength = 32
+	// projectPrefix marks hashed project names.
+	projectPrefix = "project-"
+	// hostPrefix marks hashed host names.
+	hostPrefix = "host-"
+)
+
+// Config defines which file and project names are replaced by hashes before
+// heartbeats are queued or sent.
+type Config struct {
+	// HideFileNames hides the entity of all heartbeats.
+	HideFileNames bool
+	// HideProjectNames hides the project of all heartbeats.
+	HideProjectNames bool
+	// FilePatterns hides the entity of heartbeats whose entity matches any of
+	// the patterns.
+	FilePatterns []*regexp.Regexp
+	// ProjectPatterns hides the project of heartbeats whose project or entity
+	// matches any of the patterns.
+	ProjectPatterns []*regexp.Regexp
+	// Salt is mixed into the hashes. A random salt is generated and kept in
+	// the salt file, if empty.
+	Salt string
+}
+
+// Enabled reports whether config hides anything.
+func (c Config) Enabled() bool {
+	return c.HideFileNames || c.HideProjectNames || len(c.FilePatterns) > 0 || len(c.ProjectPatterns) > 0
+}
+
+// WithObfuscation initializes and returns a heartbeat handle option, which
+// replaces hidden file and project names by stable salted hashes. Without a
+// configured salt, the salt is read from saltFilepath, and generated on first
+// use.
+func WithObfuscation(config Config, saltFilepath string) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			if !config.Enabled() {
+				return next(ctx, hs)
+			}
+
+			logger := log.Extract(ctx)
+			logger.Debugln("Execute heartbeat obfuscation")
+
+			if config.Salt == "" {
+				salt, err := LoadSalt(saltFilepath)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load privacy salt: %w", err)
+				}
+
+				config.Salt = salt
+			}
+
+			for n, h := range hs {
+				hs[n] = Obfuscate(h, config)
+			}
+
+			return next(ctx, hs)
+		}
+	}
+}
+
+// Obfuscate returns h with hidden names replaced. Hidden entities keep only the
+// extension of files, so their language stays recognizable. Files of hidden
+// projects are made relative to the project, as its path contains its name.
+// Project paths, branches and dependencies are dropped whenever a name is
+// hidden, as they give away the hidden names. Remote and local hostnames are
+// hashed along with them.
+func Obfuscate(h heartbeat.Heartbeat, config Config) heartbeat.Heartbeat {
+	hideFile := config.HideFileNames || matchesAny(config.FilePatterns, h.Entity)
+
+	hideProject := config.HideProjectNames || matchesAny(config.ProjectPatterns, h.Entity)
+	if h.Project != nil && matchesAny(config.ProjectPatterns, *h.Project) {
+		hideProject = true
+	}
+
+	if hideProject && h.Project != nil {
+		project := projectPrefix + hash(config.Salt, *h.Project)
+		h.Project = &project
+	}
+
+	if hideFile {
+		entity := hash(config.Salt, h.Entity)
+		if h.EntityType == heartbeat.FileType {
+			entity += filepath.Ext(h.Entity)
+		}
+
+		h.Entity = entity
+	} else if hideProject && h.EntityType == heartbeat.FileType {
+		h.Entity = relativeEntity(h, config.Salt)
+	}
+
+	if hideFile || hideProject {
+		h.ProjectPath = nil
+		h.Branch = nil
+		h.Dependencies = nil
+
+		if h.RemoteHost != nil {
+			host := hostPrefix + hash(config.Salt, *h.RemoteHost)
+			h.RemoteHost = &host
+		}
+
+		if h.Hostname != nil {
+			host := hostPrefix + hash(config.Salt, *h.Hostname)
+			h.Hostname = &host
+		}
+	}
+
+	return h
+}
+
+// relativeEntity returns the file entity of h relative to its project path.
+// Outside of a known project path, the directory is hashed instead.
+func relativeEntity(h heartbeat.Heartbeat, salt string) string {
+	if h.ProjectPath != nil && *h.ProjectPath != "" {
+		rel, err := filepath.Rel(*h.ProjectPath, h.Entity)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+
+	return hash(salt, filepath.Dir(h.Entity)) + "/" + filepath.Base(h.Entity)
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hash(salt, value string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))[:hashLength]
+}
+
+// LoadSalt returns the salt kept in the file at fp, generating a random one if
+// the file does not exist yet.
+func LoadSalt(fp string) (string, error) {
+	data, err := os.ReadFile(fp)
+	if err == nil && strings.TrimSpace(string(data)) != "" {
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to read salt file: %s", err)
+	}
+
+	buf := make([]byte, saltLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %s", err)
+	}
+
+	salt := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return "", fmt.Errorf("failed to create salt directory: %s", err)
+	}
+
+	if err := os.WriteFile(fp, []byte(salt+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write salt file: %s", err)
+	}
+
+	return salt, nil
+}
@@ -0,0 +1,175 @@
+package privacy_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/privacy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHeartbeat() heartbeat.Heartbeat {
+	project, projectPath, branch := "client-portal", "/home/user/client-portal", "feature/acme-invoices"
+
+	return heartbeat.Heartbeat{
+		Branch:       &branch,
+		Dependencies: []string{"github.com/acme/billing-sdk"},
+		Entity:       "/home/user/client-portal/src/billing.go",
+		Project:      &project,
+		ProjectPath:  &projectPath,
+	}
+}
+
+func TestObfuscate(t *testing.T) {
+	config := privacy.Config{HideFileNames: true, HideProjectNames: true, Salt: "salt"}
+
+	h := privacy.Obfuscate(testHeartbeat(), config)
+
+	assert.Regexp(t, `^[0-9a-f]{16}\.go$`, h.Entity)
+	require.NotNil(t, h.Project)
+	assert.Regexp(t, `^project-[0-9a-f]{16}$`, *h.Project)
+	assert.Nil(t, h.ProjectPath)
+	assert.Nil(t, h.Branch)
+	assert.Nil(t, h.Dependencies)
+
+	// hashes are stable for the same salt, and differ between salts
+	assert.Equal(t, h, privacy.Obfuscate(testHeartbeat(), config))
+
+	other := privacy.Obfuscate(testHeartbeat(), privacy.Config{HideFileNames: true, Salt: "other"})
+	assert.NotEqual(t, h.Entity, other.Entity)
+}
+
+func TestObfuscate_Patterns(t *testing.T) {
+	config := privacy.Config{
+		FilePatterns:    []*regexp.Regexp{regexp.MustCompile("/secret/")},
+		ProjectPatterns: []*regexp.Regexp{regexp.MustCompile("^client-")},
+		Salt:            "salt",
+	}
+
+	h := privacy.Obfuscate(testHeartbeat(), config)
+	assert.Equal(t, "src/billing.go", h.Entity)
+	assert.True(t, strings.HasPrefix(*h.Project, "project-"))
+	assert.Nil(t, h.ProjectPath)
+	assert.Nil(t, h.Branch)
+	assert.Nil(t, h.Dependencies)
+
+	public := testHeartbeat()
+	public.Project = nil
+	public.ProjectPath = nil
+	public.Entity = "/home/user/oss/main.go"
+
+	assert.Equal(t, public, privacy.Obfuscate(public, config))
+}
+
+func TestObfuscate_HideProjectNamesOnly(t *testing.T) {
+	config := privacy.Config{HideProjectNames: true, Salt: "salt"}
+
+	h := privacy.Obfuscate(testHeartbeat(), config)
+	assert.Equal(t, "src/billing.go", h.Entity)
+
+	data, err := json.Marshal(h)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "client-portal")
+
+	// without a project path, the directory is hashed
+	outside := testHeartbeat()
+	outside.ProjectPath = nil
+
+	h = privacy.Obfuscate(outside, config)
+	assert.Regexp(t, `^[0-9a-f]{16}/billing\.go$`, h.Entity)
+
+	data, err = json.Marshal(h)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "client-portal")
+}
+
+func TestObfuscate_NonFileEntity(t *testing.T) {
+	h := heartbeat.Heartbeat{Entity: "internal.example.com", EntityType: heartbeat.DomainType}
+
+	h = privacy.Obfuscate(h, privacy.Config{HideFileNames: true, Salt: "salt"})
+	assert.Regexp(t, `^[0-9a-f]{16}$`, h.Entity)
+}
+
+func TestObfuscate_RemoteHost(t *testing.T) {
+	host := "dev@build.acme.internal"
+
+	h := testHeartbeat()
+	h.EntityType = heartbeat.FileType
+	h.RemoteHost = &host
+
+	obfuscated := privacy.Obfuscate(h, privacy.Config{HideFileNames: true, Salt: "salt"})
+	require.NotNil(t, obfuscated.RemoteHost)
+	assert.Regexp(t, `^host-[0-9a-f]{16}$`, *obfuscated.RemoteHost)
+	assert.False(t, obfuscated.IsLocalFile())
+
+	public := privacy.Obfuscate(h, privacy.Config{
+		FilePatterns: []*regexp.Regexp{regexp.MustCompile("/secret/")},
+		Salt:         "salt",
+	})
+	assert.Equal(t, &host, public.RemoteHost)
+}
+
+func TestObfuscate_Hostname(t *testing.T) {
+	hostname := "acme-laptop"
+
+	h := testHeartbeat()
+	h.Hostname = &hostname
+
+	obfuscated := privacy.Obfuscate(h, privacy.Config{HideProjectNames: true, Salt: "salt"})
+	require.NotNil(t, obfuscated.Hostname)
+	assert.Regexp(t, `^host-[0-9a-f]{16}$`, *obfuscated.Hostname)
+
+	public := privacy.Obfuscate(h, privacy.Config{
+		ProjectPatterns: []*regexp.Regexp{regexp.MustCompile("^internal-")},
+		Salt:            "salt",
+	})
+	assert.Equal(t, &hostname, public.Hostname)
+}
+
+func TestWithObfuscation_GeneratesSalt(t *testing.T) {
+	saltFile := filepath.Join(t.TempDir(), privacy.SaltFilename)
+
+	var sent []heartbeat.Heartbeat
+	handle := privacy.WithObfuscation(privacy.Config{HideFileNames: true}, saltFile)(
+		func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			sent = append(sent, hs...)
+			return nil, nil
+		})
+
+	_, err := handle(t.Context(), []heartbeat.Heartbeat{testHeartbeat()})
+	require.NoError(t, err)
+
+	_, err = handle(t.Context(), []heartbeat.Heartbeat{testHeartbeat()})
+	require.NoError(t, err)
+
+	require.Len(t, sent, 2)
+	assert.FileExists(t, saltFile)
+	assert.Equal(t, sent[0].Entity, sent[1].Entity)
+
+	salt, err := privacy.LoadSalt(saltFile)
+	require.NoError(t, err)
+	assert.Equal(t, sent[0].Entity, privacy.Obfuscate(testHeartbeat(), privacy.Config{HideFileNames: true, Salt: salt}).Entity)
+}
+
+func TestWithObfuscation_Disabled(t *testing.T) {
+	saltFile := filepath.Join(t.TempDir(), privacy.SaltFilename)
+
+	var sent []heartbeat.Heartbeat
+	handle := privacy.WithObfuscation(privacy.Config{}, saltFile)(
+		func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			sent = append(sent, hs...)
+			return nil, nil
+		})
+
+	_, err := handle(t.Context(), []heartbeat.Heartbeat{testHeartbeat()})
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Heartbeat{testHeartbeat()}, sent)
+	assert.NoFileExists(t, saltFile)
+}
@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/statefile"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+// Filename is the default rate limit state filename.
+const Filename = "ratelimit_codebeat.json"
+
+// ErrRateLimited is returned when heartbeats are not sent, because they arrived
+// inside the rate limit window of the last sent entity.
+var ErrRateLimited = errors.New("won't send heartbeat due to rate limit")
+
+type State struct {
+	// LastSentAt is the time when heartbeats were last sent to the api.
+	LastSentAt time.Time `json:"lastSentAt"`
+	// Entity is the entity of the last sent heartbeat.
+	Entity string `json:"entity"`
+}
+
+// WithRateLimit initializes and returns a heartbeat handle option, which holds
+// back heartbeats for the entity last sent less than limit ago, by returning
+// ErrRateLimited. The state is persisted in the file at fp. A limit of 0
+// disables rate limiting.
+func WithRateLimit(fp string, limit time.Duration) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			if limit <= 0 || len(hs) == 0 {
+				return next(ctx, hs)
+			}
+
+			logger := log.Extract(ctx)
+			logger.Debugf("Execute heartbeat rate limiting with file %s", fp)
+
+			state, err := LoadState(fp)
+			if err != nil {
+				logger.Warnf("Fail to load rate limit state: %s", err)
+			}
+
+			if state.ShouldRateLimit(hs, time.Now(), limit) {
+				logger.Debugf("Rate limit heartbeat(s) for %s sent at %s", state.Entity, state.LastSentAt)
+				return nil, ErrRateLimited
+			}
+
+			results, err := next(ctx, hs)
+			if err != nil {
+				return nil, err
+			}
+
+			state = State{
+				LastSentAt: time.Now(),
+				Entity:     hs[len(hs)-1].Entity,
+			}
+
+			if err := state.Save(fp); err != nil {
+				logger.Warnf("Fail to save rate limit state: %s", err)
+			}
+
+			return results, nil
+		}
+	}
+}
+
+// ShouldRateLimit reports whether hs should be held back at now. Heartbeats are
+// only held back, if all of them belong to the last sent entity, none of them
+// is a write and the window is still open.
+func (s State) ShouldRateLimit(hs []heartbeat.Heartbeat, now time.Time, limit time.Duration) bool {
+	if s.LastSentAt.IsZero() || !now.Before(s.LastSentAt.Add(limit)) {
+		return false
+	}
+
+	for _, h := range hs {
+		if h.IsWrite || h.Entity != s.Entity {
+			return false
+		}
+	}
+
+	return true
+}
+
+// LoadState reads the rate limit state from fp. A missing file yields an empty State.
+func LoadState(fp string) (State, error) {
+	var state State
+
+	err := statefile.Read(fp, &state)
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, nil
+	}
+
+	if err != nil {
+		return State{}, fmt.Errorf("failed to read rate limit file: %s", err)
+	}
+
+	return state, nil
+}
+
+// Save writes the rate limit state to fp.
+func (s State) Save(fp string) error {
+	if err := statefile.Write(fp, s); err != nil {
+		return fmt.Errorf("failed to write rate limit file: %s", err)
+	}
+
+	return nil
+}
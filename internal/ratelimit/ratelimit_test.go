@@ -0,0 +1,77 @@
+package ratelimit_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_ShouldRateLimit(t *testing.T) {
+	sentAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	state := ratelimit.State{LastSentAt: sentAt, Entity: "/tmp/main.go"}
+
+	tests := map[string]struct {
+		Heartbeats []heartbeat.Heartbeat
+		Now        time.Time
+		Expected   bool
+	}{
+		"same entity inside window": {
+			Heartbeats: []heartbeat.Heartbeat{{Entity: "/tmp/main.go"}},
+			Now:        sentAt.Add(30 * time.Second),
+			Expected:   true,
+		},
+		"same entity after window": {
+			Heartbeats: []heartbeat.Heartbeat{{Entity: "/tmp/main.go"}},
+			Now:        sentAt.Add(2 * time.Minute),
+		},
+		"entity changed": {
+			Heartbeats: []heartbeat.Heartbeat{{Entity: "/tmp/util.go"}},
+			Now:        sentAt.Add(30 * time.Second),
+		},
+		"write event": {
+			Heartbeats: []heartbeat.Heartbeat{{Entity: "/tmp/main.go", IsWrite: true}},
+			Now:        sentAt.Add(30 * time.Second),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, state.ShouldRateLimit(test.Heartbeats, test.Now, time.Minute))
+		})
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), ratelimit.Filename)
+
+	var calls int
+	handle := ratelimit.WithRateLimit(fp, time.Minute)(
+		func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			calls++
+			return []heartbeat.Result{{Status: 201, Heartbeat: hs[0]}}, nil
+		},
+	)
+
+	hs := []heartbeat.Heartbeat{{Entity: "/tmp/main.go"}}
+
+	_, err := handle(t.Context(), hs)
+	require.NoError(t, err)
+
+	_, err = handle(t.Context(), hs)
+	require.ErrorIs(t, err, ratelimit.ErrRateLimited)
+
+	_, err = handle(t.Context(), []heartbeat.Heartbeat{{Entity: "/tmp/util.go"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, calls)
+
+	state, err := ratelimit.LoadState(fp)
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/util.go", state.Entity)
+}
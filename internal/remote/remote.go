@@ -0,0 +1,198 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+const (
+	// ContainerHost is the host of container paths, whose container is unknown.
+	ContainerHost = "container"
+	// containerWorkspaces is the folder devcontainers and codespaces mount the
+	// workspace at.
+	containerWorkspaces = "/workspaces/"
+)
+
+// Config holds the rewrite rules mapping remote paths to local ones.
+type Config struct {
+	Rewrites []Rewrite
+}
+
+// Rewrite replaces the path prefix From by To, like "/workspaces/app" by
+// "~/src/app".
+type Rewrite struct {
+	From string
+	To   string
+}
+
+// ParseRewrite parses a rewrite rule of the form "<from>=<to>".
+func ParseRewrite(s string) (Rewrite, error) {
+	from, to, ok := strings.Cut(s, "=")
+
+	from = strings.TrimSpace(from)
+	to = strings.TrimSpace(to)
+
+	if !ok || from == "" || to == "" {
+		return Rewrite{}, fmt.Errorf("invalid path rewrite %q, want <from>=<to>", s)
+	}
+
+	return Rewrite{From: strings.TrimRight(from, "/"), To: strings.TrimRight(to, "/")}, nil
+}
+
+// WithDetection initializes and returns a heartbeat handle option, which turns
+// remote file entities and project paths into a host and a path, and applies
+// the rewrite rules of config. Rewritten paths are local, so later options may
+// access the filesystem for them.
+func WithDetection(config Config) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugln("Execute remote entity detection")
+
+			for n, h := range hs {
+				if h.EntityType != heartbeat.FileType {
+					continue
+				}
+
+				hs[n] = Detect(h, config)
+
+				if hs[n].RemoteHost != nil {
+					logger.Debugf("Detected remote entity %s on host %s", hs[n].Entity, *hs[n].RemoteHost)
+				}
+			}
+
+			return next(ctx, hs)
+		}
+	}
+}
+
+// Detect returns h with remote entity and project path parsed and rewritten.
+func Detect(h heartbeat.Heartbeat, config Config) heartbeat.Heartbeat {
+	if host, fp, err := Parse(h.Entity); err == nil {
+		h.Entity = fp
+		h.RemoteHost = &host
+	}
+
+	if h.ProjectPath != nil {
+		if _, fp, err := Parse(*h.ProjectPath); err == nil {
+			h.ProjectPath = &fp
+		}
+	}
+
+	if rewritten, ok := rewrite(h.Entity, config.Rewrites); ok {
+		h.Entity = rewritten
+		h.RemoteHost = nil
+	}
+
+	if h.ProjectPath != nil {
+		if rewritten, ok := rewrite(*h.ProjectPath, config.Rewrites); ok {
+			h.ProjectPath = &rewritten
+		}
+	}
+
+	if h.RemoteHost == nil && isContainerPath(h.Entity) {
+		host := ContainerHost
+		h.RemoteHost = &host
+	}
+
+	return h
+}
+
+// Parse splits a remote uri into host and path. Supported are ssh:// and
+// sftp:// uris, and vscode-remote:// uris of ssh remotes and containers.
+func Parse(uri string) (host, fp string, err error) {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return "", "", errors.New("not a uri")
+	}
+
+	authority, fp, _ := strings.Cut(rest, "/")
+
+	authority, err = url.PathUnescape(authority)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid authority: %s", err)
+	}
+
+	fp, err = url.PathUnescape("/" + fp)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid path: %s", err)
+	}
+
+	switch strings.ToLower(scheme) {
+	case "ssh", "sftp":
+		// drop user and port
+		if _, after, ok := strings.Cut(authority, "@"); ok {
+			authority = after
+		}
+
+		host = authority
+		if h, _, ok := strings.Cut(authority, ":"); ok && !strings.HasPrefix(authority, "[") {
+			host = h
+		}
+	case "vscode-remote":
+		kind, name, _ := strings.Cut(authority, "+")
+
+		switch kind {
+		case "ssh-remote":
+			host = name
+		case "dev-container", "attached-container":
+			// names of containers are hex encoded configs, which are not stable
+			host = ContainerHost
+		default:
+			host = name
+			if host == "" {
+				host = kind
+			}
+		}
+	default:
+		return "", "", fmt.Errorf("unsupported scheme %q", scheme)
+	}
+
+	if host == "" {
+		return "", "", errors.New("missing host")
+	}
+
+	return host, fp, nil
+}
+
+// rewrite applies the rule with the longest matching prefix to fp.
+func rewrite(fp string, rules []Rewrite) (string, bool) {
+	sorted := make([]Rewrite, len(rules))
+	copy(sorted, rules)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].From) > len(sorted[j].From)
+	})
+
+	for _, rule := range sorted {
+		if fp == rule.From {
+			return rule.To, true
+		}
+
+		if rest, ok := strings.CutPrefix(fp, rule.From+"/"); ok {
+			return rule.To + "/" + rest, true
+		}
+	}
+
+	return "", false
+}
+
+// isContainerPath reports whether fp is inside the workspaces folder of a
+// container, which does not exist locally.
+func isContainerPath(fp string) bool {
+	if !strings.HasPrefix(fp, containerWorkspaces) {
+		return false
+	}
+
+	_, err := os.Stat(containerWorkspaces)
+
+	return errors.Is(err, os.ErrNotExist)
+}
@@ -0,0 +1,121 @@
+package remote_test
+
+import (
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/remote"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		URI      string
+		Host     string
+		Filepath string
+	}{
+		"vscode ssh remote": {
+			URI:      "vscode-remote://ssh-remote+build-box/home/dev/app/main.go",
+			Host:     "build-box",
+			Filepath: "/home/dev/app/main.go",
+		},
+		"vscode ssh remote escaped": {
+			URI:      "vscode-remote://ssh-remote%2Bbuild-box/home/dev/my%20app/main.go",
+			Host:     "build-box",
+			Filepath: "/home/dev/my app/main.go",
+		},
+		"vscode dev container": {
+			URI:      "vscode-remote://dev-container+7b22686f7374506174682233/workspaces/app/main.go",
+			Host:     remote.ContainerHost,
+			Filepath: "/workspaces/app/main.go",
+		},
+		"ssh": {
+			URI:      "ssh://dev@build-box:2222/srv/app/main.go",
+			Host:     "build-box",
+			Filepath: "/srv/app/main.go",
+		},
+		"sftp": {
+			URI:      "sftp://build-box/srv/app/main.go",
+			Host:     "build-box",
+			Filepath: "/srv/app/main.go",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			host, fp, err := remote.Parse(test.URI)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Host, host)
+			assert.Equal(t, test.Filepath, fp)
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, uri := range []string{"/home/dev/main.go", "https://example.com/main.go", "ssh:///srv/main.go"} {
+		_, _, err := remote.Parse(uri)
+		assert.Error(t, err, uri)
+	}
+}
+
+func TestParseRewrite(t *testing.T) {
+	rewrite, err := remote.ParseRewrite(" /workspaces/app/ = ~/src/app ")
+	require.NoError(t, err)
+	assert.Equal(t, remote.Rewrite{From: "/workspaces/app", To: "~/src/app"}, rewrite)
+
+	_, err = remote.ParseRewrite("/workspaces/app")
+	assert.Error(t, err)
+}
+
+func TestDetect(t *testing.T) {
+	config := remote.Config{Rewrites: []remote.Rewrite{
+		{From: "/workspaces", To: "/srv"},
+		{From: "/workspaces/app", To: "~/src/app"},
+	}}
+
+	host, projectPath := "build-box", "/home/dev/app"
+
+	tests := map[string]struct {
+		Heartbeat heartbeat.Heartbeat
+		Expected  heartbeat.Heartbeat
+	}{
+		"local": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "/home/dev/app/main.go"},
+			Expected:  heartbeat.Heartbeat{Entity: "/home/dev/app/main.go"},
+		},
+		"remote": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "vscode-remote://ssh-remote+build-box/home/dev/app/main.go"},
+			Expected:  heartbeat.Heartbeat{Entity: "/home/dev/app/main.go", RemoteHost: &host},
+		},
+		"remote project path": {
+			Heartbeat: heartbeat.Heartbeat{
+				Entity:      "vscode-remote://ssh-remote+build-box/home/dev/app/main.go",
+				ProjectPath: stringPtr("vscode-remote://ssh-remote+build-box/home/dev/app"),
+			},
+			Expected: heartbeat.Heartbeat{Entity: "/home/dev/app/main.go", ProjectPath: &projectPath, RemoteHost: &host},
+		},
+		"rewritten by longest prefix": {
+			Heartbeat: heartbeat.Heartbeat{
+				Entity:      "vscode-remote://dev-container+7b22/workspaces/app/main.go",
+				ProjectPath: stringPtr("/workspaces/app"),
+			},
+			Expected: heartbeat.Heartbeat{Entity: "~/src/app/main.go", ProjectPath: stringPtr("~/src/app")},
+		},
+		"rewrite on folder boundary": {
+			Heartbeat: heartbeat.Heartbeat{Entity: "/workspaces/application/main.go"},
+			Expected:  heartbeat.Heartbeat{Entity: "/srv/application/main.go"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, remote.Detect(test.Heartbeat, config))
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
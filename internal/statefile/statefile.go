@@ -0,0 +1,66 @@
+package statefile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Read decodes the json state file at fp into v. The error of a missing file
+// wraps os.ErrNotExist.
+func Read(fp string, v any) error {
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %q: %s", fp, err)
+	}
+
+	return nil
+}
+
+// Write encodes v as json into the state file at fp. The data is written to a
+// temporary file first, which then replaces fp, so that concurrently running
+// processes never read a partially written file.
+func Write(fp string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to json marshal: %s", err)
+	}
+
+	dir := filepath.Dir(fp)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %s", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(fp)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %s", err)
+	}
+
+	// no-op after a successful rename
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %s", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %s", err)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to set file mode: %s", err)
+	}
+
+	if err := os.Rename(tmp.Name(), fp); err != nil {
+		return fmt.Errorf("failed to replace %q: %s", fp, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package statefile_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/statefile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type state struct {
+	Retries int `json:"retries"`
+}
+
+func TestWriteRead(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	require.NoError(t, statefile.Write(fp, state{Retries: 3}))
+
+	var s state
+	require.NoError(t, statefile.Read(fp, &s))
+	assert.Equal(t, state{Retries: 3}, s)
+
+	info, err := os.Stat(fp)
+	require.NoError(t, err)
+	assert.True(t, info.Mode().IsRegular())
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(fp))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestRead_Missing(t *testing.T) {
+	var s state
+	err := statefile.Read(filepath.Join(t.TempDir(), "state.json"), &s)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRead_Invalid(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(fp, []byte("{"), 0644))
+
+	var s state
+	assert.ErrorContains(t, statefile.Read(fp, &s), "failed to parse")
+}
+
+func TestWrite_Concurrent(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "state.json")
+
+	var wg sync.WaitGroup
+	for n := range 20 {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, statefile.Write(fp, state{Retries: n}))
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			var s state
+			if err := statefile.Read(fp, &s); err != nil {
+				assert.ErrorIs(t, err, os.ErrNotExist)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
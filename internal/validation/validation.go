@@ -0,0 +1,173 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/pkg/log"
+)
+
+// maxClockSkew is how far heartbeat times may be ahead of the local clock,
+// before they are clamped to now.
+const maxClockSkew = time.Minute
+
+// ErrInvalid is returned when all heartbeats were rejected by validation.
+var ErrInvalid = errors.New("no valid heartbeats left after validation")
+
+type Config struct {
+	// MaxAge rejects heartbeats older than it. 0 disables the check.
+	MaxAge time.Duration
+}
+
+// WithValidation initializes and returns a heartbeat handle option, which
+// sanitizes heartbeats before they are sent or queued. Invalid fields are
+// clamped or dropped, and heartbeats which can't be fixed are rejected, each
+// with a logged reason. ErrInvalid is returned if no heartbeat is left.
+func WithValidation(config Config) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			logger := log.Extract(ctx)
+			logger.Debugln("Execute heartbeat validation")
+
+			var valid []heartbeat.Heartbeat
+
+			now := time.Now()
+
+			for _, h := range hs {
+				fixed, fixes, err := Validate(h, config, now)
+				if err != nil {
+					logger.Warnf("Rejecting heartbeat for %s: %s", h.Entity, err)
+					continue
+				}
+
+				for _, fix := range fixes {
+					logger.Infof("Sanitized heartbeat for %s: %s", h.Entity, fix)
+				}
+
+				valid = append(valid, fixed)
+			}
+
+			if len(valid) == 0 {
+				return nil, ErrInvalid
+			}
+
+			return next(ctx, valid)
+		}
+	}
+}
+
+// Validate checks h against the following rules, at time now:
+//
+//   - entity and user agent must not be empty, otherwise h is rejected. The
+//     plugin part of user agents built by the cli is never empty, as an empty
+//     plugin is replaced by a fallback when building it
+//   - time must be set and not older than config.MaxAge, otherwise h is rejected
+//   - time more than a minute ahead of now is clamped to now
+//   - lineno must be positive and is clamped to the lines in file
+//   - cursorpos must not be negative and is clamped to the size of local files
+//   - lines in file, lines added and lines removed must not be negative
+//   - empty project, project path, branch and language are dropped
+//
+// It returns the fixed heartbeat along with a description of every fix, or an
+// error describing why h is rejected.
+func Validate(h heartbeat.Heartbeat, config Config, now time.Time) (heartbeat.Heartbeat, []string, error) {
+	if strings.TrimSpace(h.Entity) == "" {
+		return h, nil, errors.New("empty entity")
+	}
+
+	if strings.TrimSpace(h.UserAgent) == "" {
+		return h, nil, errors.New("empty user agent")
+	}
+
+	if h.Time == 0 {
+		return h, nil, errors.New("missing time")
+	}
+
+	t := time.UnixMilli(int64(h.Time)).UTC()
+
+	if config.MaxAge > 0 && now.Sub(t) > config.MaxAge {
+		return h, nil, fmt.Errorf("time %s is older than %s", t.Format(time.RFC3339), config.MaxAge)
+	}
+
+	var fixes []string
+
+	if t.Sub(now) > maxClockSkew {
+		fixes = append(fixes, fmt.Sprintf("clamped time %s in the future to now", t.Format(time.RFC3339)))
+		h.Time = uint64(now.UnixMilli())
+	}
+
+	if h.LinesInFile != nil && *h.LinesInFile < 0 {
+		fixes = append(fixes, fmt.Sprintf("dropped negative lines in file %d", *h.LinesInFile))
+		h.LinesInFile = nil
+	}
+
+	if h.LineNumber != nil {
+		switch {
+		case *h.LineNumber < 1:
+			fixes = append(fixes, fmt.Sprintf("dropped lineno %d less than 1", *h.LineNumber))
+			h.LineNumber = nil
+		case h.LinesInFile != nil && *h.LinesInFile > 0 && *h.LineNumber > *h.LinesInFile:
+			fixes = append(fixes, fmt.Sprintf("clamped lineno %d to lines in file %d", *h.LineNumber, *h.LinesInFile))
+			lineNumber := *h.LinesInFile
+			h.LineNumber = &lineNumber
+		}
+	}
+
+	if h.CursorPosition != nil {
+		if *h.CursorPosition < 0 {
+			fixes = append(fixes, fmt.Sprintf("dropped negative cursorpos %d", *h.CursorPosition))
+			h.CursorPosition = nil
+		} else if size, ok := fileSize(h); ok && int64(*h.CursorPosition) > size {
+			fixes = append(fixes, fmt.Sprintf("clamped cursorpos %d to file size %d", *h.CursorPosition, size))
+			cursorPos := int(size)
+			h.CursorPosition = &cursorPos
+		}
+	}
+
+	if h.LinesAdded != nil && *h.LinesAdded < 0 {
+		fixes = append(fixes, fmt.Sprintf("dropped negative lines added %d", *h.LinesAdded))
+		h.LinesAdded = nil
+	}
+
+	if h.LinesRemoved != nil && *h.LinesRemoved < 0 {
+		fixes = append(fixes, fmt.Sprintf("dropped negative lines removed %d", *h.LinesRemoved))
+		h.LinesRemoved = nil
+	}
+
+	for _, field := range []struct {
+		Name  string
+		Value **string
+	}{
+		{"project", &h.Project},
+		{"project path", &h.ProjectPath},
+		{"branch", &h.Branch},
+		{"language", &h.Language},
+	} {
+		if *field.Value != nil && strings.TrimSpace(**field.Value) == "" {
+			fixes = append(fixes, "dropped empty "+field.Name)
+			*field.Value = nil
+		}
+	}
+
+	return h, fixes, nil
+}
+
+// fileSize returns the size in bytes of the entity of local file heartbeats.
+// Cursor positions count characters, so they never exceed it.
+func fileSize(h heartbeat.Heartbeat) (int64, bool) {
+	if !h.IsLocalFile() {
+		return 0, false
+	}
+
+	info, err := os.Stat(h.LocalFilepath())
+	if err != nil || !info.Mode().IsRegular() {
+		return 0, false
+	}
+
+	return info.Size(), true
+}
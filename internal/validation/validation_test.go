@@ -0,0 +1,196 @@
+package validation_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.UnixMilli(1585598059100)
+
+func testHeartbeat() heartbeat.Heartbeat {
+	return heartbeat.Heartbeat{
+		Entity:    "/home/user/src/main.go",
+		Time:      uint64(now.UnixMilli()),
+		UserAgent: "codeBeat/v0.1.0 (linux) go1.24.2 vscode/1.99.0",
+	}
+}
+
+func TestValidate(t *testing.T) {
+	entity := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(entity, []byte("package main\n"), 0644))
+
+	tests := map[string]struct {
+		Heartbeat func(h *heartbeat.Heartbeat)
+		Expected  func(h *heartbeat.Heartbeat)
+		Fixes     []string
+	}{
+		"valid": {
+			Heartbeat: func(h *heartbeat.Heartbeat) {
+				h.LineNumber = intPtr(12)
+				h.LinesInFile = intPtr(40)
+			},
+			Expected: func(h *heartbeat.Heartbeat) {
+				h.LineNumber = intPtr(12)
+				h.LinesInFile = intPtr(40)
+			},
+		},
+		"time in future": {
+			Heartbeat: func(h *heartbeat.Heartbeat) { h.Time += uint64(time.Hour.Milliseconds()) },
+			Expected:  func(*heartbeat.Heartbeat) {},
+			Fixes:     []string{"clamped time 2020-03-30T20:54:19Z in the future to now"},
+		},
+		"time within clock skew": {
+			Heartbeat: func(h *heartbeat.Heartbeat) { h.Time += 30_000 },
+			Expected:  func(h *heartbeat.Heartbeat) { h.Time += 30_000 },
+		},
+		"negative lineno": {
+			Heartbeat: func(h *heartbeat.Heartbeat) { h.LineNumber = intPtr(-3) },
+			Expected:  func(*heartbeat.Heartbeat) {},
+			Fixes:     []string{"dropped lineno -3 less than 1"},
+		},
+		"lineno beyond file": {
+			Heartbeat: func(h *heartbeat.Heartbeat) {
+				h.LineNumber = intPtr(90)
+				h.LinesInFile = intPtr(40)
+			},
+			Expected: func(h *heartbeat.Heartbeat) {
+				h.LineNumber = intPtr(40)
+				h.LinesInFile = intPtr(40)
+			},
+			Fixes: []string{"clamped lineno 90 to lines in file 40"},
+		},
+		"negative counts": {
+			Heartbeat: func(h *heartbeat.Heartbeat) {
+				h.LinesInFile = intPtr(-1)
+				h.LinesAdded = intPtr(-2)
+				h.LinesRemoved = intPtr(-3)
+				h.CursorPosition = intPtr(-4)
+			},
+			Expected: func(*heartbeat.Heartbeat) {},
+			Fixes: []string{
+				"dropped negative lines in file -1",
+				"dropped negative cursorpos -4",
+				"dropped negative lines added -2",
+				"dropped negative lines removed -3",
+			},
+		},
+		"cursorpos beyond file": {
+			Heartbeat: func(h *heartbeat.Heartbeat) {
+				h.Entity = entity
+				h.CursorPosition = intPtr(500)
+			},
+			Expected: func(h *heartbeat.Heartbeat) {
+				h.Entity = entity
+				h.CursorPosition = intPtr(13)
+			},
+			Fixes: []string{"clamped cursorpos 500 to file size 13"},
+		},
+		"cursorpos of missing file": {
+			Heartbeat: func(h *heartbeat.Heartbeat) { h.CursorPosition = intPtr(500) },
+			Expected:  func(h *heartbeat.Heartbeat) { h.CursorPosition = intPtr(500) },
+		},
+		"empty strings": {
+			Heartbeat: func(h *heartbeat.Heartbeat) {
+				h.Project = stringPtr("")
+				h.Branch = stringPtr(" ")
+				h.Language = stringPtr("Go")
+			},
+			Expected: func(h *heartbeat.Heartbeat) { h.Language = stringPtr("Go") },
+			Fixes:    []string{"dropped empty project", "dropped empty branch"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			h, expected := testHeartbeat(), testHeartbeat()
+			test.Heartbeat(&h)
+			test.Expected(&expected)
+
+			validated, fixes, err := validation.Validate(h, validation.Config{MaxAge: 24 * time.Hour}, now)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, validated)
+			assert.Equal(t, test.Fixes, fixes)
+		})
+	}
+}
+
+func TestValidate_Rejected(t *testing.T) {
+	tests := map[string]struct {
+		Heartbeat func(h *heartbeat.Heartbeat)
+		Error     string
+	}{
+		"empty entity": {
+			Heartbeat: func(h *heartbeat.Heartbeat) { h.Entity = " " },
+			Error:     "empty entity",
+		},
+		"empty user agent": {
+			Heartbeat: func(h *heartbeat.Heartbeat) { h.UserAgent = "" },
+			Error:     "empty user agent",
+		},
+		"missing time": {
+			Heartbeat: func(h *heartbeat.Heartbeat) { h.Time = 0 },
+			Error:     "missing time",
+		},
+		"too old": {
+			Heartbeat: func(h *heartbeat.Heartbeat) { h.Time -= uint64((48 * time.Hour).Milliseconds()) },
+			Error:     "time 2020-03-28T19:54:19Z is older than 24h0m0s",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := testHeartbeat()
+			test.Heartbeat(&h)
+
+			_, _, err := validation.Validate(h, validation.Config{MaxAge: 24 * time.Hour}, now)
+			assert.EqualError(t, err, test.Error)
+		})
+	}
+}
+
+func TestValidate_MaxAgeDisabled(t *testing.T) {
+	h := testHeartbeat()
+	h.Time = 1000
+
+	_, _, err := validation.Validate(h, validation.Config{}, now)
+	assert.NoError(t, err)
+}
+
+func TestWithValidation(t *testing.T) {
+	valid := testHeartbeat()
+	valid.Time = uint64(time.Now().UnixMilli())
+
+	invalid := valid
+	invalid.Entity = ""
+
+	var sent []heartbeat.Heartbeat
+	handle := validation.WithValidation(validation.Config{MaxAge: time.Hour})(
+		func(_ context.Context, hs []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			sent = append(sent, hs...)
+			return nil, nil
+		})
+
+	_, err := handle(t.Context(), []heartbeat.Heartbeat{invalid, valid})
+	require.NoError(t, err)
+	assert.Equal(t, []heartbeat.Heartbeat{valid}, sent)
+
+	_, err = handle(t.Context(), []heartbeat.Heartbeat{invalid, testHeartbeat()})
+	assert.ErrorIs(t, err, validation.ErrInvalid)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
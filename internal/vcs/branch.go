@@ -0,0 +1,178 @@
+package vcs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	headsPrefix = "refs/heads/"
+	// shortHashLength is the length of abbreviated commit hashes.
+	shortHashLength = 7
+)
+
+// Branch returns the checked out branch of the repository. For a detached git
+// HEAD it returns the branch pointing at the checked out commit, or the
+// abbreviated commit hash if there is none.
+func (r Repository) Branch() (string, error) {
+	switch r.Kind {
+	case Git:
+		return r.gitBranch()
+	case Mercurial:
+		return r.hgBranch()
+	default:
+		return "", fmt.Errorf("branch detection not supported for %s", r.Kind)
+	}
+}
+
+func (r Repository) gitBranch() (string, error) {
+	head, err := r.head()
+	if err != nil {
+		return "", err
+	}
+
+	if ref, ok := strings.CutPrefix(head, "ref:"); ok {
+		ref = strings.TrimSpace(ref)
+		return strings.TrimPrefix(ref, headsPrefix), nil
+	}
+
+	if !isHash(head) {
+		return "", fmt.Errorf("invalid HEAD %q", head)
+	}
+
+	refs, err := r.refs()
+	if err != nil {
+		return "", err
+	}
+
+	var branches []string
+
+	for name, hash := range refs {
+		if hash == head && strings.HasPrefix(name, headsPrefix) {
+			branches = append(branches, strings.TrimPrefix(name, headsPrefix))
+		}
+	}
+
+	if len(branches) > 0 {
+		sort.Strings(branches)
+		return branches[0], nil
+	}
+
+	return head[:shortHashLength], nil
+}
+
+// head returns the content of the HEAD file, which is either a symbolic ref
+// or a commit hash for a detached HEAD.
+func (r Repository) head() (string, error) {
+	data, err := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %s", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// refs returns all refs of the repository mapped to their commit hash. Loose
+// refs take precedence over packed refs.
+func (r Repository) refs() (map[string]string, error) {
+	refs := map[string]string{}
+
+	if err := readPackedRefs(filepath.Join(r.CommonDir, "packed-refs"), refs); err != nil {
+		return nil, err
+	}
+
+	refsDir := filepath.Join(r.CommonDir, "refs")
+
+	err := filepath.WalkDir(refsDir, func(fp string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := os.ReadFile(fp)
+		if err != nil {
+			return err
+		}
+
+		hash := strings.TrimSpace(string(data))
+		if !isHash(hash) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(r.CommonDir, fp)
+		if err != nil {
+			return err
+		}
+
+		refs[filepath.ToSlash(rel)] = hash
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read refs: %s", err)
+	}
+
+	return refs, nil
+}
+
+// readPackedRefs parses lines of "<hash> <ref>" from a packed-refs file.
+// Comments and peeled tag lines starting with "^" are skipped.
+func readPackedRefs(fp string, refs map[string]string) error {
+	f, err := os.Open(fp)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open packed-refs: %s", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+
+		hash, ref, ok := strings.Cut(line, " ")
+		if ok && isHash(hash) {
+			refs[ref] = hash
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (r Repository) hgBranch() (string, error) {
+	data, err := os.ReadFile(filepath.Join(r.Root, ".hg", "branch"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "default", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read hg branch: %s", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// isHash reports whether s is a sha1 or sha256 hex object name.
+func isHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,568 @@
+package vcs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+
+	// maxDeltaDepth is the longest chain of deltas resolved for an object.
+	maxDeltaDepth = 64
+	// maxTreeSize is the size of the largest tree or commit object read.
+	maxTreeSize = 16 * 1024 * 1024
+)
+
+var (
+	// ErrNotTracked is returned for files, which are not part of the HEAD commit.
+	ErrNotTracked = errors.New("file not tracked at HEAD")
+	// ErrObjectTooLarge is returned for objects exceeding the size limit.
+	ErrObjectTooLarge = errors.New("object exceeds size limit")
+
+	packIdxMagic = []byte{0xff, 't', 'O', 'c'}
+)
+
+// HeadBlob returns the content of the file at fp, as committed at the HEAD of
+// the git repository. Blobs larger than maxSize return ErrObjectTooLarge.
+func (r Repository) HeadBlob(fp string, maxSize int64) ([]byte, error) {
+	if r.Kind != Git {
+		return nil, fmt.Errorf("reading blobs not supported for %s", r.Kind)
+	}
+
+	abs, err := filepath.Abs(fp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute path: %s", err)
+	}
+
+	rel, err := filepath.Rel(r.Root, abs)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil, ErrNotTracked
+	}
+
+	commit, err := r.headCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	typ, data, err := r.readObject(commit, maxTreeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", commit, err)
+	}
+
+	if typ != objCommit {
+		return nil, fmt.Errorf("HEAD %s is not a commit", commit)
+	}
+
+	tree, err := commitTree(data)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := r.lookupPath(tree, filepath.ToSlash(rel))
+	if err != nil {
+		return nil, err
+	}
+
+	typ, data, err = r.readObject(hash, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", hash, err)
+	}
+
+	if typ != objBlob {
+		return nil, ErrNotTracked
+	}
+
+	return data, nil
+}
+
+// headCommit returns the hash of the checked out commit.
+func (r Repository) headCommit() (string, error) {
+	head, err := r.head()
+	if err != nil {
+		return "", err
+	}
+
+	ref, ok := strings.CutPrefix(head, "ref:")
+	if !ok {
+		if !isHash(head) {
+			return "", fmt.Errorf("invalid HEAD %q", head)
+		}
+
+		return head, nil
+	}
+
+	return r.resolveRef(strings.TrimSpace(ref))
+}
+
+// resolveRef returns the commit hash a ref points at.
+func (r Repository) resolveRef(ref string) (string, error) {
+	refs, err := r.refs()
+	if err != nil {
+		return "", err
+	}
+
+	hash, ok := refs[ref]
+	if !ok {
+		return "", fmt.Errorf("ref %s not found", ref)
+	}
+
+	return hash, nil
+}
+
+// lookupPath walks the tree objects from tree along the slash separated path
+// and returns the hash of the entry found.
+func (r Repository) lookupPath(tree, path string) (string, error) {
+	hash := tree
+
+	for _, name := range strings.Split(path, "/") {
+		typ, data, err := r.readObject(hash, maxTreeSize)
+		if err != nil {
+			return "", fmt.Errorf("failed to read tree %s: %w", hash, err)
+		}
+
+		if typ != objTree {
+			return "", ErrNotTracked
+		}
+
+		hash, err = treeEntry(data, name, len(tree)/2)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return hash, nil
+}
+
+// commitTree returns the tree hash of a commit object.
+func commitTree(data []byte) (string, error) {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+
+	tree, ok := bytes.CutPrefix(line, []byte("tree "))
+	if !ok || !isHash(string(tree)) {
+		return "", errors.New("invalid commit object")
+	}
+
+	return string(tree), nil
+}
+
+// treeEntry returns the hash of the entry called name in a tree object, whose
+// entries are of the form "<mode> <name>\0<binary hash>".
+func treeEntry(data []byte, name string, hashLen int) (string, error) {
+	for len(data) > 0 {
+		sp := bytes.IndexByte(data, ' ')
+		if sp < 0 {
+			break
+		}
+
+		nul := bytes.IndexByte(data[sp:], 0)
+		if nul < 0 || sp+nul+1+hashLen > len(data) {
+			break
+		}
+
+		entry := string(data[sp+1 : sp+nul])
+		hash := data[sp+nul+1 : sp+nul+1+hashLen]
+
+		if entry == name {
+			return hex.EncodeToString(hash), nil
+		}
+
+		data = data[sp+nul+1+hashLen:]
+	}
+
+	return "", ErrNotTracked
+}
+
+// readObject returns the type and content of the object hash, looking up loose
+// objects first and packfiles second.
+func (r Repository) readObject(hash string, maxSize int64) (int, []byte, error) {
+	return r.readObjectDepth(hash, maxSize, 0)
+}
+
+func (r Repository) readObjectDepth(hash string, maxSize int64, depth int) (int, []byte, error) {
+	objectsDir := filepath.Join(r.CommonDir, "objects")
+
+	typ, data, err := readLooseObject(filepath.Join(objectsDir, hash[:2], hash[2:]), maxSize)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return typ, data, err
+	}
+
+	idxFiles, err := filepath.Glob(filepath.Join(objectsDir, "pack", "*.idx"))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sort.Strings(idxFiles)
+
+	for _, idx := range idxFiles {
+		offset, ok, err := findPackOffset(idx, hash)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		pack := &packFile{
+			repo:    r,
+			path:    strings.TrimSuffix(idx, ".idx") + ".pack",
+			hashLen: len(hash) / 2,
+			maxSize: maxSize,
+		}
+
+		return pack.readObject(offset, depth)
+	}
+
+	return 0, nil, fmt.Errorf("object %s not found: %w", hash, fs.ErrNotExist)
+}
+
+// readLooseObject reads a zlib compressed object of the form
+// "<type> <size>\0<content>".
+func readLooseObject(fp string, maxSize int64) (int, []byte, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to decompress object: %s", err)
+	}
+	defer zr.Close()
+
+	br := bufio.NewReader(zr)
+
+	header, err := br.ReadString(0)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read object header: %s", err)
+	}
+
+	name, sizeStr, ok := strings.Cut(strings.TrimSuffix(header, "\x00"), " ")
+	if !ok {
+		return 0, nil, fmt.Errorf("invalid object header %q", header)
+	}
+
+	size, err := strconv.ParseInt(sizeStr, 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid object size %q", sizeStr)
+	}
+
+	if size > maxSize {
+		return 0, nil, ErrObjectTooLarge
+	}
+
+	var typ int
+
+	switch name {
+	case "commit":
+		typ = objCommit
+	case "tree":
+		typ = objTree
+	case "blob":
+		typ = objBlob
+	case "tag":
+		typ = objTag
+	default:
+		return 0, nil, fmt.Errorf("invalid object type %q", name)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return 0, nil, fmt.Errorf("failed to read object: %s", err)
+	}
+
+	return typ, data, nil
+}
+
+// findPackOffset looks up hash in a version 2 pack index and returns the offset
+// of the object in the corresponding packfile.
+func findPackOffset(idxPath, hash string) (int64, bool, error) {
+	want, err := hex.DecodeString(hash)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid hash %q", hash)
+	}
+
+	f, err := os.Open(idxPath)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to open pack index: %s", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, 8+256*4)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return 0, false, fmt.Errorf("failed to read pack index header: %s", err)
+	}
+
+	if !bytes.Equal(header[:4], packIdxMagic) || binary.BigEndian.Uint32(header[4:8]) != 2 {
+		return 0, false, fmt.Errorf("unsupported pack index %s", idxPath)
+	}
+
+	fanout := header[8:]
+	total := int64(binary.BigEndian.Uint32(fanout[255*4:]))
+
+	var lo int64
+	if want[0] > 0 {
+		lo = int64(binary.BigEndian.Uint32(fanout[(int(want[0])-1)*4:]))
+	}
+
+	hi := int64(binary.BigEndian.Uint32(fanout[int(want[0])*4:]))
+
+	hashLen := int64(len(want))
+	namesOffset := int64(len(header))
+	name := make([]byte, hashLen)
+
+	for lo < hi {
+		mid := (lo + hi) / 2
+
+		if _, err := f.ReadAt(name, namesOffset+mid*hashLen); err != nil {
+			return 0, false, fmt.Errorf("failed to read pack index: %s", err)
+		}
+
+		switch cmp := bytes.Compare(name, want); {
+		case cmp < 0:
+			lo = mid + 1
+		case cmp > 0:
+			hi = mid
+		default:
+			return readPackIndexOffset(f, namesOffset+total*hashLen+total*4, total, mid)
+		}
+	}
+
+	return 0, false, nil
+}
+
+// readPackIndexOffset reads the offset of the n-th object, which is either a
+// 31 bit offset or an index into the table of 64 bit offsets.
+func readPackIndexOffset(f *os.File, offsetsOffset, total, n int64) (int64, bool, error) {
+	buf := make([]byte, 8)
+
+	if _, err := f.ReadAt(buf[:4], offsetsOffset+n*4); err != nil {
+		return 0, false, fmt.Errorf("failed to read pack index offset: %s", err)
+	}
+
+	offset := binary.BigEndian.Uint32(buf[:4])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true, nil
+	}
+
+	large := int64(offset & 0x7fffffff)
+
+	if _, err := f.ReadAt(buf, offsetsOffset+total*4+large*8); err != nil {
+		return 0, false, fmt.Errorf("failed to read pack index large offset: %s", err)
+	}
+
+	return int64(binary.BigEndian.Uint64(buf)), true, nil
+}
+
+type packFile struct {
+	repo    Repository
+	path    string
+	hashLen int
+	maxSize int64
+}
+
+// readObject reads the object at offset, resolving deltas against their base
+// objects.
+func (p *packFile) readObject(offset int64, depth int) (int, []byte, error) {
+	if depth > maxDeltaDepth {
+		return 0, nil, errors.New("delta chain too deep")
+	}
+
+	f, err := os.Open(p.path)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to open packfile: %s", err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+
+	c, err := br.ReadByte()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read pack object header: %s", err)
+	}
+
+	typ := int(c>>4) & 7
+	size := int64(c & 0x0f)
+
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, fmt.Errorf("failed to read pack object header: %s", err)
+		}
+
+		size |= int64(c&0x7f) << shift
+	}
+
+	var (
+		baseOffset int64
+		baseHash   string
+	)
+
+	switch typ {
+	case objCommit, objTree, objBlob, objTag:
+		if size > p.maxSize {
+			return 0, nil, ErrObjectTooLarge
+		}
+	case objOfsDelta:
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, fmt.Errorf("failed to read delta offset: %s", err)
+		}
+
+		rel := int64(c & 0x7f)
+
+		for c&0x80 != 0 {
+			if c, err = br.ReadByte(); err != nil {
+				return 0, nil, fmt.Errorf("failed to read delta offset: %s", err)
+			}
+
+			rel = ((rel + 1) << 7) | int64(c&0x7f)
+		}
+
+		baseOffset = offset - rel
+	case objRefDelta:
+		hash := make([]byte, p.hashLen)
+		if _, err := io.ReadFull(br, hash); err != nil {
+			return 0, nil, fmt.Errorf("failed to read delta base: %s", err)
+		}
+
+		baseHash = hex.EncodeToString(hash)
+	default:
+		return 0, nil, fmt.Errorf("invalid pack object type %d", typ)
+	}
+
+	// deltas are at most slightly larger than the object they result in
+	if size > 2*p.maxSize+1024 {
+		return 0, nil, ErrObjectTooLarge
+	}
+
+	zr, err := zlib.NewReader(br)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to decompress pack object: %s", err)
+	}
+	defer zr.Close()
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return 0, nil, fmt.Errorf("failed to read pack object: %s", err)
+	}
+
+	var (
+		baseType int
+		base     []byte
+	)
+
+	switch typ {
+	case objOfsDelta:
+		baseType, base, err = p.readObject(baseOffset, depth+1)
+	case objRefDelta:
+		baseType, base, err = p.repo.readObjectDepth(baseHash, p.maxSize, depth+1)
+	default:
+		return typ, data, nil
+	}
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result, err := applyDelta(base, data, p.maxSize)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return baseType, result, nil
+}
+
+// applyDelta applies a git delta, made of copy and insert instructions, to base.
+func applyDelta(base, delta []byte, maxSize int64) ([]byte, error) {
+	errInvalid := errors.New("invalid delta")
+
+	srcSize, n := binary.Uvarint(delta)
+	if n <= 0 || srcSize != uint64(len(base)) {
+		return nil, errInvalid
+	}
+
+	delta = delta[n:]
+
+	dstSize, n := binary.Uvarint(delta)
+	if n <= 0 {
+		return nil, errInvalid
+	}
+
+	if dstSize > uint64(maxSize) {
+		return nil, ErrObjectTooLarge
+	}
+
+	delta = delta[n:]
+	result := make([]byte, 0, dstSize)
+
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case op&0x80 != 0:
+			var offset, size uint64
+
+			for i := 0; i < 7; i++ {
+				if op&(1<<i) == 0 {
+					continue
+				}
+
+				if len(delta) == 0 {
+					return nil, errInvalid
+				}
+
+				if i < 4 {
+					offset |= uint64(delta[0]) << (8 * i)
+				} else {
+					size |= uint64(delta[0]) << (8 * (i - 4))
+				}
+
+				delta = delta[1:]
+			}
+
+			if size == 0 {
+				size = 0x10000
+			}
+
+			if offset+size > uint64(len(base)) {
+				return nil, errInvalid
+			}
+
+			result = append(result, base[offset:offset+size]...)
+		case op != 0:
+			if int(op) > len(delta) {
+				return nil, errInvalid
+			}
+
+			result = append(result, delta[:op]...)
+			delta = delta[op:]
+		default:
+			return nil, errInvalid
+		}
+	}
+
+	if uint64(len(result)) != dstSize {
+		return nil, errInvalid
+	}
+
+	return result, nil
+}
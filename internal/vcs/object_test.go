@@ -0,0 +1,129 @@
+package vcs_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeObject writes a loose git object and returns its hash.
+func writeObject(t *testing.T, gitDir, typ string, content []byte) string {
+	data := append([]byte(fmt.Sprintf("%s %d\x00", typ, len(content))), content...)
+	sum := sha1.Sum(data)
+	hash := hex.EncodeToString(sum[:])
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, err := zw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	writeFile(t, filepath.Join(gitDir, "objects", hash[:2], hash[2:]), buf.String())
+
+	return hash
+}
+
+func treeEntry(t *testing.T, mode, name, hash string) []byte {
+	raw, err := hex.DecodeString(hash)
+	require.NoError(t, err)
+
+	return append([]byte(mode+" "+name+"\x00"), raw...)
+}
+
+func TestRepository_HeadBlob(t *testing.T) {
+	root := t.TempDir()
+	gitDir := filepath.Join(root, ".git")
+
+	blob := writeObject(t, gitDir, "blob", []byte("package main\n"))
+	subtree := writeObject(t, gitDir, "tree", treeEntry(t, "100644", "main.go", blob))
+	tree := writeObject(t, gitDir, "tree", treeEntry(t, "40000", "src", subtree))
+	commit := writeObject(t, gitDir, "commit", []byte("tree "+tree+"\n\ninitial commit\n"))
+
+	writeFile(t, filepath.Join(gitDir, "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(gitDir, "refs", "heads", "main"), commit+"\n")
+
+	repo, ok := vcs.Find(filepath.Join(root, "src", "main.go"))
+	require.True(t, ok)
+
+	data, err := repo.HeadBlob(filepath.Join(root, "src", "main.go"), 1024)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n", string(data))
+
+	_, err = repo.HeadBlob(filepath.Join(root, "src", "untracked.go"), 1024)
+	assert.ErrorIs(t, err, vcs.ErrNotTracked)
+
+	_, err = repo.HeadBlob(filepath.Join(root, "src", "main.go"), 4)
+	assert.ErrorIs(t, err, vcs.ErrObjectTooLarge)
+}
+
+func TestRepository_HeadBlobPacked(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not available")
+	}
+
+	root := t.TempDir()
+	fp := filepath.Join(root, "main.go")
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = root
+
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+
+		return strings.TrimSpace(string(out))
+	}
+
+	var lines []string
+	for n := 0; n < 200; n++ {
+		lines = append(lines, fmt.Sprintf("line %d", n))
+	}
+
+	first := strings.Join(lines, "\n") + "\n"
+	lines[100] = "changed line"
+	second := strings.Join(lines, "\n") + "\n"
+
+	git("init", "-q", "-b", "main")
+	writeFile(t, fp, first)
+	git("add", "main.go")
+	git("commit", "-q", "-m", "first")
+	firstCommit := git("rev-parse", "HEAD")
+	writeFile(t, fp, second)
+	git("commit", "-q", "-a", "-m", "second")
+
+	// pack all objects and refs, storing one version of main.go as delta
+	git("gc", "-q", "--aggressive", "--prune=now")
+
+	repo, ok := vcs.Find(fp)
+	require.True(t, ok)
+
+	data, err := repo.HeadBlob(fp, 1024*1024)
+	require.NoError(t, err)
+	assert.Equal(t, second, string(data))
+
+	// detached HEAD at the first commit
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), firstCommit+"\n")
+
+	data, err = repo.HeadBlob(fp, 1024*1024)
+	require.NoError(t, err)
+	assert.Equal(t, first, string(data))
+}
+
+func TestMain(m *testing.M) {
+	// keep git from reading the config of the user running the tests
+	os.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	os.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,177 @@
+package vcs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kind is the type of version control system.
+type Kind string
+
+const (
+	// Git is a git working tree, including worktrees and submodules.
+	Git Kind = "git"
+	// Mercurial is a mercurial working copy.
+	Mercurial Kind = "hg"
+	// Subversion is a subversion working copy.
+	Subversion Kind = "svn"
+)
+
+// Repository is the version controlled folder a file belongs to.
+type Repository struct {
+	Kind Kind
+	// Name is the project name of the repository.
+	Name string
+	// Root is the top level folder of the working tree.
+	Root string
+	// GitDir is the git directory of the working tree. For worktrees and
+	// submodules it is the directory referenced by the .git file.
+	GitDir string
+	// CommonDir is the git directory holding refs and objects, which is shared
+	// between a repository and its worktrees.
+	CommonDir string
+}
+
+// Find walks up from fp and returns the closest repository containing it.
+func Find(fp string) (Repository, bool) {
+	abs, err := filepath.Abs(fp)
+	if err != nil {
+		return Repository{}, false
+	}
+
+	dir := filepath.Dir(abs)
+	if info, err := os.Stat(abs); err == nil && info.IsDir() {
+		dir = abs
+	}
+
+	for {
+		if repo, ok := findGit(dir); ok {
+			return repo, true
+		}
+
+		if isDir(filepath.Join(dir, ".hg")) {
+			return Repository{
+				Kind: Mercurial,
+				Name: filepath.Base(dir),
+				Root: dir,
+			}, true
+		}
+
+		if isDir(filepath.Join(dir, ".svn")) {
+			root := svnRoot(dir)
+
+			return Repository{
+				Kind: Subversion,
+				Name: filepath.Base(root),
+				Root: root,
+			}, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Repository{}, false
+		}
+
+		dir = parent
+	}
+}
+
+func findGit(dir string) (Repository, bool) {
+	dotGit := filepath.Join(dir, ".git")
+
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return Repository{}, false
+	}
+
+	if info.IsDir() {
+		return Repository{
+			Kind:      Git,
+			Name:      filepath.Base(dir),
+			Root:      dir,
+			GitDir:    dotGit,
+			CommonDir: dotGit,
+		}, true
+	}
+
+	// worktrees and submodules have a .git file pointing to their git directory
+	gitDir, err := readGitDirFile(dotGit)
+	if err != nil {
+		return Repository{}, false
+	}
+
+	repo := Repository{
+		Kind:      Git,
+		Name:      filepath.Base(dir),
+		Root:      dir,
+		GitDir:    gitDir,
+		CommonDir: gitDir,
+	}
+
+	commonDir, err := readRelativePath(filepath.Join(gitDir, "commondir"), gitDir)
+	if err == nil {
+		// a worktree belongs to the project of its main working tree
+		repo.CommonDir = commonDir
+
+		if filepath.Base(commonDir) == ".git" {
+			repo.Name = filepath.Base(filepath.Dir(commonDir))
+		}
+	}
+
+	return repo, true
+}
+
+// readGitDirFile parses a .git file of the form "gitdir: <path>".
+func readGitDirFile(fp string) (string, error) {
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(string(data))
+
+	gitDir, ok := strings.CutPrefix(line, "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("invalid .git file %q", fp)
+	}
+
+	return resolvePath(strings.TrimSpace(gitDir), filepath.Dir(fp)), nil
+}
+
+// readRelativePath reads a path from fp, which is resolved relative to base.
+func readRelativePath(fp, base string) (string, error) {
+	data, err := os.ReadFile(fp)
+	if err != nil {
+		return "", err
+	}
+
+	return resolvePath(strings.TrimSpace(string(data)), base), nil
+}
+
+func resolvePath(p, base string) string {
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(base, p)
+	}
+
+	return filepath.Clean(p)
+}
+
+// svnRoot returns the top most folder of a subversion working copy, as
+// subversion before 1.7 kept a .svn folder in every directory.
+func svnRoot(dir string) string {
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir || !isDir(filepath.Join(parent, ".svn")) {
+			return dir
+		}
+
+		dir = parent
+	}
+}
+
+func isDir(fp string) bool {
+	info, err := os.Stat(fp)
+	return err == nil && info.IsDir()
+}
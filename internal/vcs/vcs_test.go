@@ -0,0 +1,187 @@
+package vcs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/vcs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mkdirAll(t *testing.T, paths ...string) {
+	for _, p := range paths {
+		require.NoError(t, os.MkdirAll(p, 0755))
+	}
+}
+
+func writeFile(t *testing.T, fp, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(fp), 0755))
+	require.NoError(t, os.WriteFile(fp, []byte(content), 0644))
+}
+
+func TestFind(t *testing.T) {
+	tmp := t.TempDir()
+
+	// plain git repository
+	mainRepo := filepath.Join(tmp, "codebeat")
+	mkdirAll(t, filepath.Join(mainRepo, ".git"), filepath.Join(mainRepo, "src"))
+
+	// worktree of the plain repository
+	worktree := filepath.Join(tmp, "codebeat-feature")
+	worktreeGitDir := filepath.Join(mainRepo, ".git", "worktrees", "codebeat-feature")
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: "+worktreeGitDir+"\n")
+	writeFile(t, filepath.Join(worktreeGitDir, "commondir"), "../..\n")
+
+	// submodule inside the plain repository
+	submodule := filepath.Join(mainRepo, "third_party", "lib")
+	writeFile(t, filepath.Join(submodule, ".git"), "gitdir: ../../.git/modules/lib\n")
+	mkdirAll(t, filepath.Join(mainRepo, ".git", "modules", "lib"))
+
+	// mercurial and nested old style subversion working copies
+	hgRepo := filepath.Join(tmp, "hgproject")
+	mkdirAll(t, filepath.Join(hgRepo, ".hg"))
+
+	svnRepo := filepath.Join(tmp, "svnproject")
+	mkdirAll(t, filepath.Join(svnRepo, ".svn"), filepath.Join(svnRepo, "trunk", ".svn"))
+
+	tests := map[string]struct {
+		Entity   string
+		Expected vcs.Repository
+	}{
+		"git": {
+			Entity: filepath.Join(mainRepo, "src", "main.go"),
+			Expected: vcs.Repository{
+				Kind:      vcs.Git,
+				Name:      "codebeat",
+				Root:      mainRepo,
+				GitDir:    filepath.Join(mainRepo, ".git"),
+				CommonDir: filepath.Join(mainRepo, ".git"),
+			},
+		},
+		"git worktree": {
+			Entity: filepath.Join(worktree, "main.go"),
+			Expected: vcs.Repository{
+				Kind:      vcs.Git,
+				Name:      "codebeat",
+				Root:      worktree,
+				GitDir:    worktreeGitDir,
+				CommonDir: filepath.Join(mainRepo, ".git"),
+			},
+		},
+		"git submodule": {
+			Entity: filepath.Join(submodule, "lib.go"),
+			Expected: vcs.Repository{
+				Kind:      vcs.Git,
+				Name:      "lib",
+				Root:      submodule,
+				GitDir:    filepath.Join(mainRepo, ".git", "modules", "lib"),
+				CommonDir: filepath.Join(mainRepo, ".git", "modules", "lib"),
+			},
+		},
+		"mercurial": {
+			Entity: filepath.Join(hgRepo, "main.py"),
+			Expected: vcs.Repository{
+				Kind: vcs.Mercurial,
+				Name: "hgproject",
+				Root: hgRepo,
+			},
+		},
+		"subversion": {
+			Entity: filepath.Join(svnRepo, "trunk", "main.c"),
+			Expected: vcs.Repository{
+				Kind: vcs.Subversion,
+				Name: "svnproject",
+				Root: svnRepo,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo, ok := vcs.Find(test.Entity)
+			require.True(t, ok)
+
+			assert.Equal(t, test.Expected, repo)
+		})
+	}
+}
+
+func TestFind_NotFound(t *testing.T) {
+	_, ok := vcs.Find(filepath.Join(t.TempDir(), "main.go"))
+	assert.False(t, ok)
+}
+
+func TestRepository_Branch(t *testing.T) {
+	const (
+		mainHash    = "8f2c1e0f6b1d5a0c9f3e4d2b1a0c9e8d7f6a5b4c"
+		featureHash = "1a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d"
+		orphanHash  = "ffeeddccbbaa99887766554433221100ffeeddcc"
+	)
+
+	tests := map[string]struct {
+		Head     string
+		Expected string
+	}{
+		"symbolic ref": {
+			Head:     "ref: refs/heads/feature/login\n",
+			Expected: "feature/login",
+		},
+		"detached head on loose ref": {
+			Head:     mainHash + "\n",
+			Expected: "main",
+		},
+		"detached head on packed ref": {
+			Head:     featureHash + "\n",
+			Expected: "feature",
+		},
+		"detached head without branch": {
+			Head:     orphanHash + "\n",
+			Expected: "ffeeddc",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			gitDir := filepath.Join(root, ".git")
+
+			writeFile(t, filepath.Join(gitDir, "HEAD"), test.Head)
+			writeFile(t, filepath.Join(gitDir, "refs", "heads", "main"), mainHash+"\n")
+			writeFile(t, filepath.Join(gitDir, "packed-refs"),
+				"# pack-refs with: peeled fully-peeled sorted\n"+
+					featureHash+" refs/heads/feature\n"+
+					"^"+orphanHash+"\n")
+
+			repo, ok := vcs.Find(filepath.Join(root, "main.go"))
+			require.True(t, ok)
+
+			branch, err := repo.Branch()
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, branch)
+		})
+	}
+}
+
+func TestRepository_BranchWorktree(t *testing.T) {
+	tmp := t.TempDir()
+
+	mainGitDir := filepath.Join(tmp, "codebeat", ".git")
+	worktreeGitDir := filepath.Join(mainGitDir, "worktrees", "feature")
+	worktree := filepath.Join(tmp, "feature")
+
+	writeFile(t, filepath.Join(mainGitDir, "HEAD"), "ref: refs/heads/main\n")
+	writeFile(t, filepath.Join(worktreeGitDir, "HEAD"), "ref: refs/heads/feature\n")
+	writeFile(t, filepath.Join(worktreeGitDir, "commondir"), "../..\n")
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: "+worktreeGitDir+"\n")
+
+	repo, ok := vcs.Find(filepath.Join(worktree, "main.go"))
+	require.True(t, ok)
+
+	branch, err := repo.Branch()
+	require.NoError(t, err)
+
+	assert.Equal(t, "feature", branch)
+}
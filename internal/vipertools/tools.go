@@ -1,6 +1,7 @@
 package vipertools
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -9,3 +10,33 @@ import (
 func GetString(v *viper.Viper, key string) string {
 	return strings.Trim(v.GetString(key), `"'`)
 }
+
+// GetFlatStringMap returns the config section at key as a map of strings. As viper
+// splits keys at dots, nested sections are joined back into dotted keys.
+func GetFlatStringMap(v *viper.Viper, key string) map[string]string {
+	result := map[string]string{}
+
+	section, ok := v.Get(key).(map[string]any)
+	if !ok {
+		return result
+	}
+
+	flatten("", section, result)
+
+	return result
+}
+
+func flatten(prefix string, section map[string]any, result map[string]string) {
+	for k, value := range section {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+
+		if nested, ok := value.(map[string]any); ok {
+			flatten(k, nested, result)
+			continue
+		}
+
+		result[k] = strings.Trim(fmt.Sprint(value), `"'`)
+	}
+}
@@ -8,12 +8,23 @@ import (
 var (
 	backslashReplaceRegex = regexp.MustCompile(`[\\/]+`)
 	windowsDriveRegex     = regexp.MustCompile("^[a-z]:/")
+	uncPrefixRegex        = regexp.MustCompile(`^[\\/]{2}[^\\/]`)
 )
 
-// FormatFilePath formats a windows filepath by converting backslash
+// FormatFilePath formats a windows filepath by converting backslashes to
+// forward slashes and upper casing the drive letter. The double slash of UNC
+// paths like \\server\share is kept.
 func FormatFilePath(fp string) string {
+	prefix := ""
+	if uncPrefixRegex.MatchString(fp) {
+		prefix = "/"
+	}
+
+	fp = prefix + backslashReplaceRegex.ReplaceAllString(fp, "/")
+
 	if windowsDriveRegex.MatchString(fp) {
 		fp = strings.ToUpper(fp[:1]) + fp[1:]
 	}
-	return backslashReplaceRegex.ReplaceAllString(fp, "/")
+
+	return fp
 }
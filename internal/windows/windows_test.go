@@ -0,0 +1,52 @@
+package windows_test
+
+import (
+	"testing"
+
+	"github.com/result17/codeBeatCli/internal/windows"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatFilePath(t *testing.T) {
+	tests := map[string]string{
+		`c:\Users\dev\main.go`:   "C:/Users/dev/main.go",
+		`C:\\Users\\dev\main.go`: "C:/Users/dev/main.go",
+		`d:/src\app/main.go`:     "D:/src/app/main.go",
+		`\\server\share\main.go`: "//server/share/main.go",
+		"/home/dev/main.go":      "/home/dev/main.go",
+	}
+
+	for fp, expected := range tests {
+		t.Run(fp, func(t *testing.T) {
+			assert.Equal(t, expected, windows.FormatFilePath(fp))
+		})
+	}
+}
+
+func TestToWSLPath(t *testing.T) {
+	tests := map[string]struct {
+		Expected   string
+		Translated bool
+	}{
+		`C:\Users\dev\main.go`:           {"/mnt/c/Users/dev/main.go", true},
+		`d:/src\app/main.go`:             {"/mnt/d/src/app/main.go", true},
+		`C:\`:                            {"/mnt/c", true},
+		`C:`:                             {"/mnt/c", true},
+		`\\wsl$\Ubuntu\home\dev\main.go`: {"/home/dev/main.go", true},
+		`\\wsl.localhost\Ubuntu-22.04\home\dev\a.go`: {"/home/dev/a.go", true},
+		`//wsl$/Debian/srv/app`:                      {"/srv/app", true},
+		`\\wsl$\Ubuntu`:                              {"/", true},
+		`\\server\share\main.go`:                     {`\\server\share\main.go`, false},
+		"/home/dev/main.go":                          {"/home/dev/main.go", false},
+		"Cargo.toml":                                 {"Cargo.toml", false},
+	}
+
+	for fp, test := range tests {
+		t.Run(fp, func(t *testing.T) {
+			translated, ok := windows.ToWSLPath(fp)
+
+			assert.Equal(t, test.Expected, translated)
+			assert.Equal(t, test.Translated, ok)
+		})
+	}
+}
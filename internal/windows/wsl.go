@@ -0,0 +1,59 @@
+package windows
+
+import (
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	wslDriveRegex = regexp.MustCompile(`^([a-zA-Z]):(?:[\\/]|$)`)
+	wslShareRegex = regexp.MustCompile(`(?i)^[\\/]{2}(?:wsl\$|wsl\.localhost)[\\/]+[^\\/]+(.*)$`)
+)
+
+// osReleaseFile holds the kernel release, which names microsoft under WSL.
+const osReleaseFile = "/proc/sys/kernel/osrelease"
+
+// IsWSL reports whether the current process runs inside the Windows Subsystem
+// for Linux.
+func IsWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" || os.Getenv("WSL_INTEROP") != "" {
+		return true
+	}
+
+	release, err := os.ReadFile(osReleaseFile)
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
+
+// ToWSLPath translates a windows filepath into the path it is reachable at
+// from inside WSL. Drive paths like C:\Users\dev map to /mnt/c/Users/dev, and
+// paths of the WSL share like \\wsl$\Ubuntu\home\dev map to /home/dev. Other
+// paths are returned unchanged, along with false.
+func ToWSLPath(fp string) (string, bool) {
+	if m := wslShareRegex.FindStringSubmatch(fp); m != nil {
+		rest := backslashReplaceRegex.ReplaceAllString(m[1], "/")
+		if rest == "" {
+			rest = "/"
+		}
+
+		return rest, true
+	}
+
+	if m := wslDriveRegex.FindStringSubmatch(fp); m != nil {
+		rest := strings.TrimLeft(fp[len(m[0]):], `\/`)
+		rest = backslashReplaceRegex.ReplaceAllString(rest, "/")
+
+		translated := "/mnt/" + strings.ToLower(m[1])
+		if rest != "" {
+			translated += "/" + rest
+		}
+
+		return translated, true
+	}
+
+	return fp, false
+}
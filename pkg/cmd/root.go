@@ -41,15 +41,31 @@ func setFlags(cmd *cobra.Command, v *viper.Viper) {
 	flags.String(
 		"entity",
 		"",
-		"Absolute path to file for the heartbeat.",
+		"Absolute path to file for the heartbeat, or a remote uri like vscode-remote://ssh-remote+host/path. "+
+			"Can also be an app, domain, url or terminal session, see --entity-type.",
+	)
+	flags.String(
+		"entity-type",
+		"",
+		"Entity type for this heartbeat. Can be \"file\", \"app\", \"domain\", \"url\" or \"terminal\". Defaults to \"file\".(Optional)",
 	)
 	flags.String("api-url", "", "Optional api baseurl.")
 	flags.String("language", "", "The language or file format of entity.")
 	flags.String("alternate-project", "", "Alternate project name.(Optional)")
+	flags.String("branch", "", "Branch name. Detected from the git repository by default.(Optional)")
+	flags.String(
+		"category",
+		"",
+		"Category of this heartbeat activity. Can be \"coding\", \"debugging\", \"building\", \"reviewing\" or \"testing\". Defaults to \"coding\".(Optional)",
+	)
 	flags.String("config", "", "Plugin config file.(Optional)")
 	flags.BoolP("version", "v", false, "Print CodeBeatCli version, and exit.")
 	flags.Bool("dlog", false, "Set debugger logger level.")
-	flags.Bool("local-save", false, "Save hearbeat record in local db buck.(Optional)")
+	flags.Bool(
+		"local-save",
+		false,
+		"Save hearbeat record in local db buck. Hidden names stay obfuscated, unless local-save-real-names is set in the [privacy] config section.(Optional)",
+	)
 	flags.Int("cursorpos", 0, "Cursor position in the current file for the heartbeat.(Optional)")
 	flags.Int("lineno", 0, "Current line number int the file.")
 	flags.Int(
@@ -58,14 +74,104 @@ func setFlags(cmd *cobra.Command, v *viper.Viper) {
 		"The total line count of file for the heartbeat.",
 	)
 	flags.String("log-file", "", "Plugin log file absolute path.(Optional)")
+	flags.String(
+		"hostname",
+		"",
+		"Name of the machine this heartbeat was sent from. Defaults to the local hostname. "+
+			"Pass an empty value to omit it.(Optional)",
+	)
+	flags.String(
+		"local-file",
+		"",
+		"Absolute path to a temporary copy of an unsaved buffer, which is read instead of the entity. "+
+			"The entity is still reported.(Optional)",
+	)
 	flags.String("project-path", "", "Absolute path to project folder.(Optional)")
 	flags.String("log-filer", "", "Absolute path to plugin log file.(Optional)")
-	flags.Int64("time", 0, "Unix epoch timeStamp. Uses current time by default.")
+	flags.String(
+		"time",
+		"",
+		"Time of the heartbeat, as unix epoch timestamp in seconds, milliseconds or microseconds, "+
+			"or RFC3339 string. Fractions like 1585598059.1 are allowed. Uses current time by default or when 0.",
+	)
 	flags.String("plugin", "", "Text editor plugin name and version")
+	flags.StringArray(
+		"exclude",
+		nil,
+		"Regex pattern of entities or projects, which are not tracked. Can be repeated.(Optional)",
+	)
+	flags.StringArray(
+		"include",
+		nil,
+		"Regex pattern of entities or projects, which are tracked exclusively. Can be repeated.(Optional)",
+	)
+	flags.Bool(
+		"include-only-with-project-file",
+		false,
+		"Only track entities inside a folder containing a .codebeat-project file.(Optional)",
+	)
+	flags.Bool(
+		"extra-heartbeats",
+		false,
+		"Reads extra heartbeats from STDIN as a JSON array, and sends them together with the main heartbeat.(Optional)",
+	)
+	flags.Bool(
+		"hide-file-names",
+		false,
+		"Obfuscate file names with a salted hash, keeping the extension. "+
+			"Config section [privacy] takes hide-file-names patterns to only hide matching files.(Optional)",
+	)
+	flags.Bool(
+		"hide-project-names",
+		false,
+		"Obfuscate project names with a salted hash. "+
+			"Config section [privacy] takes hide-project-names patterns to only hide matching projects.(Optional)",
+	)
+	flags.StringArray(
+		"path-rewrite",
+		nil,
+		"Rewrite rule of the form <from>=<to>, which maps remote or container paths to local ones, "+
+			"like /workspaces/app=~/src/app. Can be repeated.(Optional)",
+	)
+	flags.Bool(
+		"no-resolve-symlinks",
+		false,
+		"Keep symlinks in entity and project paths, instead of resolving them to their target.(Optional)",
+	)
+	flags.String(
+		"wsl-path-translation",
+		"auto",
+		"Translate windows paths to their WSL location, like C:\\Users to /mnt/c/Users and "+
+			"\\\\wsl$\\<distro>\\home to /home. Can be \"auto\", \"on\" or \"off\". "+
+			"Defaults to \"auto\", which translates when running inside WSL.(Optional)",
+	)
+	flags.Bool("write", false, "When set, tells api this heartbeat was triggered from writing to a file.(Optional)")
+	flags.Int(
+		"heartbeat-rate-limit-seconds",
+		0,
+		"Only send one heartbeat per entity every N seconds, queueing the others offline. "+
+			"Writes and entity changes are always sent. 0 disables rate limiting.(Optional)",
+	)
+	flags.Int(
+		"heartbeat-max-age-days",
+		30,
+		"Reject heartbeats older than N days, instead of sending them. 0 disables the check.(Optional)",
+	)
 
 	flags.Bool("today-duration", false, "Query today's coding duration")
 	flags.Bool("today-summary", false, "Query today's summary")
-	flags.String("today-metric-duration", "", "Query today's coding duration by metric")
+	flags.String(
+		"today-metric-duration",
+		"",
+		"Query today's coding duration by metric. "+
+			"Can be \"project\", \"lineno\", \"entityType\", \"category\", \"churn\" or \"hostname\". "+
+			"\"churn\" reports lines added and removed per project instead of durations.",
+	)
+	flags.Int(
+		"sync-offline-activity",
+		0,
+		"Sync up to N heartbeats from the offline queue to the api, and exit. 0 syncs the whole queue.",
+	)
 
 	err := v.BindPFlags(flags)
 	if err != nil {
@@ -12,12 +12,14 @@ import (
 	"github.com/spf13/viper"
 	"go.uber.org/zap/zapcore"
 
+	"github.com/result17/codeBeatCli/internal/config"
 	"github.com/result17/codeBeatCli/internal/version"
 	"github.com/result17/codeBeatCli/pkg/duration"
 	heartbeat "github.com/result17/codeBeatCli/pkg/entity"
 	"github.com/result17/codeBeatCli/pkg/exitcode"
 	"github.com/result17/codeBeatCli/pkg/log"
 	metricPkg "github.com/result17/codeBeatCli/pkg/metric"
+	offlinePkg "github.com/result17/codeBeatCli/pkg/offline"
 	"github.com/result17/codeBeatCli/pkg/summary"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
@@ -35,6 +37,11 @@ func RunE(cmd *cobra.Command, v *viper.Viper) error {
 	}
 	ctx = log.ToContxt(ctx, logger)
 
+	if err := config.ReadInConfig(ctx, v); err != nil {
+		logger.Errorf("Failed to load config: %s", err)
+		return exitcode.Err{Code: exitcode.ErrConfigFileParse}
+	}
+
 	if v.GetBool("version") {
 		logger.Debugln("command: version")
 		return runCmd(ctx, v, version.RunVersion)
@@ -42,8 +49,12 @@ func RunE(cmd *cobra.Command, v *viper.Viper) error {
 
 	if entity := v.GetString("entity"); entity != "" {
 		logger.Debugln("Command: heartbeat")
-		_, err := heartbeat.Run(ctx, v)
-		return err
+		return runCmd(ctx, v, heartbeat.Run)
+	}
+
+	if v.IsSet("sync-offline-activity") {
+		logger.Debugln("Command: sync-offline-activity")
+		return runCmd(ctx, v, offlinePkg.Run)
 	}
 
 	if v.GetBool("today-duration") {
@@ -2,8 +2,13 @@ package heartbeat
 
 import (
 	"context"
+	"errors"
 
+	"github.com/result17/codeBeatCli/internal/backoff"
+	"github.com/result17/codeBeatCli/internal/filter"
 	"github.com/result17/codeBeatCli/internal/offline"
+	"github.com/result17/codeBeatCli/internal/ratelimit"
+	"github.com/result17/codeBeatCli/internal/validation"
 	"github.com/result17/codeBeatCli/pkg/exitcode"
 	"github.com/result17/codeBeatCli/pkg/log"
 	"github.com/spf13/viper"
@@ -18,6 +23,26 @@ func Run(ctx context.Context, v *viper.Viper) (int, error) {
 
 	err = SendHeartbeats(ctx, v, queueFilepath)
 	if err != nil {
+		if errors.Is(err, backoff.ErrBackoff) {
+			logger.Debugln("Heartbeat(s) queued due to backoff")
+			return exitcode.ErrBackoff, nil
+		}
+
+		if errors.Is(err, filter.ErrSkipped) {
+			logger.Debugln("All heartbeat(s) skipped by filters")
+			return exitcode.ErrSkipped, nil
+		}
+
+		if errors.Is(err, validation.ErrInvalid) {
+			logger.Debugln("All heartbeat(s) rejected by validation")
+			return exitcode.ErrInvalid, nil
+		}
+
+		if errors.Is(err, ratelimit.ErrRateLimited) {
+			logger.Debugln("Heartbeat(s) queued due to rate limit")
+			return exitcode.Success, nil
+		}
+
 		logger.Debugf("Fail to sent heartbeat(s): %s", err)
 		return exitcode.ErrAPI, nil
 	}
@@ -3,12 +3,21 @@ package heartbeat
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"runtime"
 	"strings"
 
-	"github.com/matishsiao/goInfo"
+	"github.com/result17/codeBeatCli/internal/backoff"
+	"github.com/result17/codeBeatCli/internal/deps"
+	"github.com/result17/codeBeatCli/internal/filter"
 	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/language"
 	"github.com/result17/codeBeatCli/internal/offline"
+	"github.com/result17/codeBeatCli/internal/platform"
+	"github.com/result17/codeBeatCli/internal/privacy"
+	"github.com/result17/codeBeatCli/internal/ratelimit"
+	"github.com/result17/codeBeatCli/internal/remote"
+	"github.com/result17/codeBeatCli/internal/validation"
 	"github.com/result17/codeBeatCli/internal/version"
 	apiCmd "github.com/result17/codeBeatCli/pkg/api"
 	"github.com/result17/codeBeatCli/pkg/log"
@@ -29,13 +38,8 @@ func SendHeartbeats(ctx context.Context, v *viper.Viper, path string) error {
 	logger := log.Extract(ctx)
 	setLogFields(logger, h)
 
-	opts := initHandleOptions(h)
-	if isSave := v.GetBool("local-save"); isSave {
-		opts = append(opts, offline.SaveHeartbeat(path))
-	}
-	heartbeats := buildHeartbeats(ctx, h)
-	// TODO RateLimit
-	// TODO backoff handler
+	opts := initHandleOptions(h, path)
+	heartbeats := buildHeartbeats(ctx, h, path)
 
 	apiClient, err := apiCmd.NewClient(ctx, apiParams.BaseUrl)
 
@@ -67,53 +71,37 @@ func setLogFields(logger *log.Logger, params params.Heartbeat) {
 	}
 }
 
-func UserAgent(ctx context.Context, plugin string) string {
-	logger := log.Extract(ctx)
-
-	template := "codeBeat/%s (%s-%s-%s) %s %s"
-
-	if plugin == "" {
-		plugin = "codeBeat-v0/"
+// defaultPlugin stands in for the plugin part of user agents, when no plugin
+// is passed.
+const defaultPlugin = "codeBeat-v0/"
+
+// UserAgent returns the user agent of heartbeats sent by plugin, describing the
+// platform detected by the platform package, like
+// "codeBeat/v0.1.0 (linux-6.8.0-45-generic-amd64; Ubuntu 24.04 LTS) go1.24.2 vscode/1.99.0".
+// The platform info is cached in the file at cacheFilepath. An empty plugin is
+// replaced by defaultPlugin, which is logged like the fixes of validation.
+func UserAgent(ctx context.Context, plugin, cacheFilepath string) string {
+	if strings.TrimSpace(plugin) == "" {
+		log.Extract(ctx).Infof("Sanitized user agent: empty plugin replaced by %q", defaultPlugin)
+		plugin = defaultPlugin
 	}
 
-	info, err := goInfo.GetInfo()
-	if err != nil {
-		logger.Debugf("GoInfo.GetInfo error: %s", err)
-	}
+	info := platform.Load(ctx, cacheFilepath)
 
-	userAgent := fmt.Sprintf(
-		template,
+	return fmt.Sprintf(
+		"codeBeat/%s (%s) %s %s",
 		version.Version,
-		// TODO system pkg
-		"windows",
-		strings.TrimSpace(info.Core),
-		strings.TrimSpace(info.Platform),
+		info,
 		strings.TrimSpace(runtime.Version()),
 		strings.TrimSpace(plugin),
 	)
-
-	defer func() {
-		if r := recover(); r != nil {
-			userAgent = fmt.Sprintf(
-				template,
-				version.Version,
-				// TODO system pkg
-				"windows",
-				"unknown",
-				"unknown",
-				strings.TrimSpace(runtime.Version()),
-				strings.TrimSpace(plugin),
-			)
-		}
-	}()
-	return userAgent
 }
 
-func buildHeartbeats(ctx context.Context, params params.Heartbeat) []heartbeat.Heartbeat {
+func buildHeartbeats(ctx context.Context, params params.Heartbeat, queueFilepath string) []heartbeat.Heartbeat {
 	heartbeats := []heartbeat.Heartbeat{}
-	userAgent := UserAgent(ctx, params.Plugin)
+	userAgent := UserAgent(ctx, params.Plugin, filepath.Join(filepath.Dir(queueFilepath), platform.CacheFilename))
 
-	heartbeats = append(heartbeats, *heartbeat.New(
+	h := heartbeat.New(
 		params.Entity,
 		userAgent,
 		params.Time,
@@ -123,13 +111,64 @@ func buildHeartbeats(ctx context.Context, params params.Heartbeat) []heartbeat.H
 		params.LineInFile,
 		params.AlternateProject,
 		params.ProjectFolder,
-	))
+	)
+	h.Branch = params.Branch
+	h.Category = params.Category
+	h.EntityType = params.EntityType
+	h.Hostname = params.Hostname
+	h.IsWrite = params.IsWrite
+	h.LocalFile = params.LocalFile
+
+	heartbeats = append(heartbeats, *h)
+
+	for _, extra := range params.ExtraHeartbeats {
+		if extra.UserAgent == "" {
+			extra.UserAgent = userAgent
+		}
+
+		if extra.Hostname == nil {
+			extra.Hostname = params.Hostname
+		}
+
+		heartbeats = append(heartbeats, extra)
+	}
+
 	return heartbeats
 }
 
-func initHandleOptions(params params.Heartbeat) []heartbeat.HandleOption {
+func initHandleOptions(params params.Heartbeat, queueFilepath string) []heartbeat.HandleOption {
+	// state files are kept beside the offline queue, in ~/.codebeat by default
+	stateDir := filepath.Dir(queueFilepath)
+
 	opts := []heartbeat.HandleOption{
-		heartbeat.WithFormatting(),
+		remote.WithDetection(params.Remote),
+		heartbeat.WithFormatting(params.Format),
+		heartbeat.WithLinesInFile(),
+		validation.WithValidation(params.Validation),
+		heartbeat.WithProjectDetection(),
+		filter.WithFiltering(params.Filter),
+		filter.WithIgnoreFiles(filepath.Join(stateDir, filter.IgnoreCacheFilename)),
+		language.WithNormalization(params.Plugin, params.LanguageConfig),
+		language.WithDetection(params.LanguageConfig),
+		deps.WithDetection(),
+		heartbeat.WithLineChanges(),
+	}
+
+	// the local copy only keeps real names before obfuscation, if explicitly enabled
+	if params.LocalSave && params.LocalSaveRealNames {
+		opts = append(opts, offline.SaveHeartbeat(queueFilepath))
 	}
+
+	opts = append(opts,
+		privacy.WithObfuscation(params.Privacy, filepath.Join(stateDir, privacy.SaltFilename)),
+		offline.WithQueue(queueFilepath),
+		ratelimit.WithRateLimit(filepath.Join(stateDir, ratelimit.Filename), params.RateLimit),
+		backoff.WithBackoff(filepath.Join(stateDir, backoff.Filename)),
+	)
+
+	if params.LocalSave && !params.LocalSaveRealNames {
+		opts = append(opts, offline.SaveHeartbeat(queueFilepath))
+	}
+
 	return opts
 }
@@ -9,6 +9,14 @@ const (
 	ErrGeneric = 1
 	// ErrAPI is when API returned an error
 	ErrAPI = 102
+	// ErrConfigFileParse is used when the config file could not be parsed
+	ErrConfigFileParse = 103
+	// ErrBackoff is used when heartbeats were queued, because of backoff after api failures
+	ErrBackoff = 112
+	// ErrSkipped is used when all heartbeats were skipped by include and exclude filters
+	ErrSkipped = 113
+	// ErrInvalid is used when all heartbeats were rejected by validation
+	ErrInvalid = 114
 )
 
 type Err struct {
@@ -42,8 +42,10 @@ func Run[T string | uint32](ctx context.Context, v *viper.Viper) (int, error) {
 
 func TypeRun(ctx context.Context, v *viper.Viper, metricKey string) (int, error) {
 	switch metricKey {
-	case "project":
+	case "project", "entityType", "category", "hostname":
 		return Run[string](ctx, v)
+	case "churn":
+		return RunChurn(ctx, v)
 	case "lineno":
 		return Run[uint32](ctx, v)
 	default:
@@ -64,3 +66,48 @@ func TodayMetricDuration[T string | uint32](ctx context.Context, v *viper.Viper)
 	}
 	return data, nil
 }
+
+// RunChurn executes the today-metric-duration command for churn, which reports
+// lines added and removed per project instead of durations.
+func RunChurn(ctx context.Context, v *viper.Viper) (int, error) {
+	logger := log.Extract(ctx)
+	data, err := TodayChurn(ctx, v)
+	if err != nil {
+		logger.Errorf("Failed fetched today churn, %s", err)
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"Today churn fetch failed: %s",
+			err,
+		)
+	}
+
+	logger.Debugln("Successfully fetched today churn")
+
+	output, err := json.Marshal(data)
+	if err != nil {
+		logger.Errorf("Failed marshal churn, %s, %+v", err, data)
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"Churn marshal failed: %s",
+			err,
+		)
+	}
+	fmt.Println(string(output))
+	return exitcode.Success, nil
+}
+
+func TodayChurn(ctx context.Context, v *viper.Viper) (*metric.MetricChurnData, error) {
+	apiParams, err := params.LoadApiParams(ctx, v)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to load api params: %s", err)
+	}
+
+	apiClient, err := apiCmd.NewClient(ctx, apiParams.BaseUrl)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to create apiClient: %s", err)
+	}
+
+	data, err := api.QueryTodayChurn(apiClient, ctx, v)
+	if err != nil {
+		return nil, fmt.Errorf("Fail to query today's churn: %s", err)
+	}
+	return data, nil
+}
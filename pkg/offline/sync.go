@@ -0,0 +1,56 @@
+package offline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/result17/codeBeatCli/internal/offline"
+	apiCmd "github.com/result17/codeBeatCli/pkg/api"
+	"github.com/result17/codeBeatCli/pkg/exitcode"
+	"github.com/result17/codeBeatCli/pkg/log"
+	"github.com/result17/codeBeatCli/pkg/params"
+	"github.com/spf13/viper"
+)
+
+// Run executes the sync-offline-activity command.
+func Run(ctx context.Context, v *viper.Viper) (int, error) {
+	logger := log.Extract(ctx)
+	queueFilepath, err := offline.QueueFilepath(ctx, v)
+	if err != nil {
+		logger.Warnf("Fail to load offline queue filepath: %s", err)
+	}
+
+	synced, err := SyncOfflineActivity(ctx, v, queueFilepath)
+	if err != nil {
+		logger.Errorf("Failed to sync offline activity, %s", err)
+		return exitcode.ErrAPI, fmt.Errorf(
+			"Offline sync failed: %s",
+			err,
+		)
+	}
+
+	logger.Debugf("Successfully synced %d offline heartbeat(s)", synced)
+
+	return exitcode.Success, nil
+}
+
+// SyncOfflineActivity sends heartbeats queued in the offline queue at queueFilepath
+// to the api, and returns the number of processed heartbeats.
+func SyncOfflineActivity(ctx context.Context, v *viper.Viper, queueFilepath string) (int, error) {
+	offlineParams, err := params.LoadOfflineParams(ctx, v)
+	if err != nil {
+		return 0, fmt.Errorf("Fail to load offline parameters: %s", err)
+	}
+
+	apiParams, err := params.LoadApiParams(ctx, v)
+	if err != nil {
+		return 0, fmt.Errorf("Fail to load api parameters: %s", err)
+	}
+
+	apiClient, err := apiCmd.NewClient(ctx, apiParams.BaseUrl)
+	if err != nil {
+		return 0, fmt.Errorf("Fail to create apiClient: %s", err)
+	}
+
+	return offline.Sync(ctx, queueFilepath, offlineParams.SyncMax, apiClient)
+}
@@ -2,11 +2,26 @@ package params
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/result17/codeBeatCli/internal/api"
+	"github.com/result17/codeBeatCli/internal/filter"
+	"github.com/result17/codeBeatCli/internal/heartbeat"
+	"github.com/result17/codeBeatCli/internal/language"
+	"github.com/result17/codeBeatCli/internal/privacy"
+	"github.com/result17/codeBeatCli/internal/remote"
+	"github.com/result17/codeBeatCli/internal/validation"
 	"github.com/result17/codeBeatCli/internal/vipertools"
+	"github.com/result17/codeBeatCli/internal/windows"
+	"github.com/result17/codeBeatCli/pkg/log"
 	"github.com/spf13/viper"
 )
 
@@ -20,18 +35,40 @@ type (
 		BaseUrl string
 	}
 
+	Offline struct {
+		SyncMax int
+	}
+
 	Heartbeat struct {
 		Entity           string
+		EntityType       heartbeat.EntityType
 		Plugin           string
 		Language         *string
 		LinesNumber      *int
 		CursorPos        *int
 		LineInFile       *int
 		AlternateProject *string
+		Branch           *string
+		Category         heartbeat.Category
+		Hostname         *string
 		ProjectFolder    *string
 		Config           *string
 		LogFile          *string
 		Time             uint64
+		IsWrite          bool
+		LocalSave        bool
+		LocalFile        string
+		// LocalSaveRealNames keeps real file and project names in the local
+		// copy of heartbeats, while obfuscating them for the api.
+		LocalSaveRealNames bool
+		RateLimit          time.Duration
+		ExtraHeartbeats    []heartbeat.Heartbeat
+		LanguageConfig     language.Config
+		Filter             filter.Config
+		Format             heartbeat.FormatConfig
+		Privacy            privacy.Config
+		Remote             remote.Config
+		Validation         validation.Config
 	}
 )
 
@@ -65,6 +102,16 @@ func LoadApiParams(ctx context.Context, v *viper.Viper) (API, error) {
 	}, nil
 }
 
+func LoadOfflineParams(ctx context.Context, v *viper.Viper) (Offline, error) {
+	syncMax := v.GetInt("sync-offline-activity")
+	if syncMax < 0 {
+		return Offline{}, fmt.Errorf("sync-offline-activity must be zero or positive, got %d", syncMax)
+	}
+	return Offline{
+		SyncMax: syncMax,
+	}, nil
+}
+
 func loadHeartbeatParams(ctx context.Context, v *viper.Viper) (Heartbeat, error) {
 	var cursorPos *int
 	if v.IsSet("cursorpos") {
@@ -76,6 +123,24 @@ func loadHeartbeatParams(ctx context.Context, v *viper.Viper) (Heartbeat, error)
 		return Heartbeat{}, errors.New("fail to receive entity")
 	}
 
+	entityType := heartbeat.FileType
+	if v.IsSet("entity-type") {
+		parsed, err := heartbeat.ParseEntityType(vipertools.GetString(v, "entity-type"))
+		if err != nil {
+			return Heartbeat{}, fmt.Errorf("failed to parse entity-type: %s", err)
+		}
+		entityType = parsed
+	}
+
+	category := heartbeat.CodingCategory
+	if c := vipertools.GetString(v, "category"); c != "" {
+		parsed, err := heartbeat.ParseCategory(c)
+		if err != nil {
+			return Heartbeat{}, fmt.Errorf("failed to parse category: %s", err)
+		}
+		category = parsed
+	}
+
 	var linesNumber *int
 	if v.IsSet("lineno") {
 		linesNumber = PointerTo(v.GetInt("lineno"))
@@ -98,28 +163,274 @@ func loadHeartbeatParams(ctx context.Context, v *viper.Viper) (Heartbeat, error)
 		projectFolder = PointerTo(vipertools.GetString(v, "project-path"))
 	}
 
-	var language *string
+	var branch *string
+	if b := vipertools.GetString(v, "branch"); b != "" {
+		branch = &b
+	}
+
+	// an explicitly empty hostname opts out of sending one
+	var hostname *string
+	if h := vipertools.GetString(v, "hostname"); h != "" {
+		hostname = &h
+	} else if v.IsSet("hostname") {
+		log.Extract(ctx).Debugln("Hostname disabled")
+	} else if h, err := os.Hostname(); err != nil {
+		log.Extract(ctx).Warnf("Failed to get hostname: %s", err)
+	} else if h = strings.TrimSpace(h); h != "" {
+		hostname = &h
+	}
+
+	var lang *string
 	if l := vipertools.GetString(v, "language"); l != "" {
-		language = &l
+		lang = &l
 	}
 
-	// default now
-	timeVal := time.Now().UnixMilli()
-	if v.IsSet("time") {
-		if secs := v.GetInt64("time"); secs > 0 {
-			timeVal = secs
+	// default now, also for plugins passing 0 or an empty time
+	timeVal := uint64(time.Now().UnixMilli())
+	if t := vipertools.GetString(v, "time"); !isZeroTime(t) {
+		parsed, err := heartbeat.ParseTime(t)
+		if err != nil {
+			return Heartbeat{}, err
 		}
+
+		timeVal = parsed
+	}
+
+	maxAgeDays := v.GetInt("heartbeat-max-age-days")
+	if maxAgeDays < 0 {
+		return Heartbeat{}, fmt.Errorf("heartbeat-max-age-days must be zero or positive, got %d", maxAgeDays)
+	}
+
+	translateWSLPaths, err := parseWSLPathTranslation(vipertools.GetString(v, "wsl-path-translation"))
+	if err != nil {
+		return Heartbeat{}, err
+	}
+
+	patterns := map[string][]*regexp.Regexp{}
+	for _, key := range []string{"exclude", "include", "privacy.hide-file-names", "privacy.hide-project-names"} {
+		compiled, err := compilePatterns(v, key)
+		if err != nil {
+			return Heartbeat{}, err
+		}
+
+		patterns[key] = compiled
+	}
+
+	rateLimitSecs := v.GetInt("heartbeat-rate-limit-seconds")
+	if rateLimitSecs < 0 {
+		return Heartbeat{}, fmt.Errorf("heartbeat-rate-limit-seconds must be zero or positive, got %d", rateLimitSecs)
+	}
+
+	var extraHeartbeats []heartbeat.Heartbeat
+	if v.GetBool("extra-heartbeats") {
+		// a broken stdin must not cost the main heartbeat
+		hs, err := readExtraHeartbeats(ctx, os.Stdin)
+		if err != nil {
+			log.Extract(ctx).Warnf("Ignoring extra heartbeats: %s", err)
+		}
+		extraHeartbeats = hs
 	}
 
 	return Heartbeat{
-		Entity:           entity,
-		Plugin:           plugin,
-		LinesNumber:      linesNumber,
-		CursorPos:        cursorPos,
-		LineInFile:       lineInFile,
-		AlternateProject: alternateProject,
-		ProjectFolder:    projectFolder,
-		Time:             uint64(timeVal),
-		Language:         language,
+		Entity:             entity,
+		EntityType:         entityType,
+		Plugin:             plugin,
+		LinesNumber:        linesNumber,
+		CursorPos:          cursorPos,
+		LineInFile:         lineInFile,
+		AlternateProject:   alternateProject,
+		Branch:             branch,
+		Category:           category,
+		Hostname:           hostname,
+		ProjectFolder:      projectFolder,
+		Time:               timeVal,
+		Language:           lang,
+		IsWrite:            v.GetBool("write"),
+		LocalSave:          v.GetBool("local-save"),
+		LocalFile:          vipertools.GetString(v, "local-file"),
+		LocalSaveRealNames: v.GetBool("privacy.local-save-real-names"),
+		RateLimit:          time.Duration(rateLimitSecs) * time.Second,
+		ExtraHeartbeats:    extraHeartbeats,
+		LanguageConfig: language.Config{
+			Extensions: vipertools.GetFlatStringMap(v, "languages.extensions"),
+			Filenames:  vipertools.GetFlatStringMap(v, "languages.filenames"),
+			Aliases:    loadLanguageAliases(v),
+		},
+		Filter: filter.Config{
+			Exclude:                    patterns["exclude"],
+			Include:                    patterns["include"],
+			IncludeOnlyWithProjectFile: v.GetBool("include-only-with-project-file"),
+		},
+		Format: heartbeat.FormatConfig{
+			NoResolveSymlinks: v.GetBool("no-resolve-symlinks"),
+			TranslateWSLPaths: translateWSLPaths,
+		},
+		Privacy: privacy.Config{
+			HideFileNames:    v.GetBool("hide-file-names"),
+			HideProjectNames: v.GetBool("hide-project-names"),
+			FilePatterns:     patterns["privacy.hide-file-names"],
+			ProjectPatterns:  patterns["privacy.hide-project-names"],
+			Salt:             vipertools.GetString(v, "privacy.salt"),
+		},
+		Remote: remote.Config{
+			Rewrites: parseRewrites(ctx, v.GetStringSlice("path-rewrite")),
+		},
+		Validation: validation.Config{
+			MaxAge: time.Duration(maxAgeDays) * 24 * time.Hour,
+		},
 	}, nil
 }
+
+// parseWSLPathTranslation reports whether windows paths are translated to WSL
+// paths. Mode "auto", the default, translates when running inside WSL.
+func parseWSLPathTranslation(mode string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", "auto":
+		return windows.IsWSL(), nil
+	case "on", "true":
+		return true, nil
+	case "off", "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid wsl-path-translation %q, want auto, on or off", mode)
+	}
+}
+
+// compilePatterns compiles the case insensitive regular expressions of the
+// config key. An invalid pattern fails, as skipping it would track or send what
+// it was meant to exclude or hide.
+func compilePatterns(v *viper.Viper, key string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+
+	patterns := v.GetStringSlice(key)
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern %q: %s", key, pattern, err)
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+// parseRewrites parses path rewrite rules of the form "<from>=<to>". Invalid
+// rules are skipped with a warning.
+func parseRewrites(ctx context.Context, rules []string) []remote.Rewrite {
+	logger := log.Extract(ctx)
+
+	var rewrites []remote.Rewrite
+
+	for _, rule := range rules {
+		rewrite, err := remote.ParseRewrite(rule)
+		if err != nil {
+			logger.Warnf("Skipping path rewrite: %s", err)
+			continue
+		}
+
+		rewrites = append(rewrites, rewrite)
+	}
+
+	return rewrites
+}
+
+// loadLanguageAliases reads the languages.aliases config section. Keys of the
+// form "<editor>.<id>" apply to one editor family, plain ids to all editors.
+func loadLanguageAliases(v *viper.Viper) map[string]map[string]string {
+	aliases := map[string]map[string]string{}
+
+	for key, lang := range vipertools.GetFlatStringMap(v, "languages.aliases") {
+		editor, id, ok := strings.Cut(key, ".")
+		if !ok {
+			editor, id = "", key
+		}
+
+		if aliases[editor] == nil {
+			aliases[editor] = map[string]string{}
+		}
+
+		aliases[editor][id] = lang
+	}
+
+	return aliases
+}
+
+// extraHeartbeat is a heartbeat read from stdin, whose time is accepted in any
+// of the formats of heartbeat.ParseTime.
+type extraHeartbeat struct {
+	heartbeat.Heartbeat
+	Time json.RawMessage `json:"time"`
+}
+
+// readExtraHeartbeats decodes a json array of heartbeats from r. Heartbeats
+// without entity or time are skipped.
+func readExtraHeartbeats(ctx context.Context, r io.Reader) ([]heartbeat.Heartbeat, error) {
+	logger := log.Extract(ctx)
+
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to json decode extra heartbeats: %s", err)
+	}
+
+	var hs []heartbeat.Heartbeat
+
+	for n, data := range raw {
+		var extra extraHeartbeat
+		if err := json.Unmarshal(data, &extra); err != nil {
+			logger.Warnf("Skipping extra heartbeat #%d: %s", n, err)
+			continue
+		}
+
+		h := extra.Heartbeat
+
+		if h.Entity == "" {
+			logger.Warnf("Skipping extra heartbeat #%d: missing entity", n)
+			continue
+		}
+
+		t := string(extra.Time)
+		if err := json.Unmarshal(extra.Time, &t); err != nil {
+			// not a json string, but a number or null
+			t = string(extra.Time)
+		}
+
+		if t == "null" || isZeroTime(t) {
+			logger.Warnf("Skipping extra heartbeat #%d: missing time", n)
+			continue
+		}
+
+		parsed, err := heartbeat.ParseTime(t)
+		if err != nil {
+			logger.Warnf("Skipping extra heartbeat #%d: %s", n, err)
+			continue
+		}
+
+		h.Time = parsed
+
+		hs = append(hs, h)
+	}
+
+	logger.Debugf("Read %d extra heartbeat(s) from stdin", len(hs))
+
+	return hs, nil
+}
+
+// isZeroTime reports whether s is an empty or zero time, which plugins pass
+// when they have no time at hand.
+func isZeroTime(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return true
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+
+	return err == nil && f == 0
+}
@@ -0,0 +1,179 @@
+package params_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/result17/codeBeatCli/pkg/params"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadParams_Patterns(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/home/user/src/main.go")
+	v.Set("exclude", []string{"^/tmp/", " "})
+	v.Set("privacy.hide-project-names", []string{"^client-"})
+
+	p, err := params.LoadParams(t.Context(), v)
+	require.NoError(t, err)
+
+	require.Len(t, p.Heartbeat.Filter.Exclude, 1)
+	assert.True(t, p.Heartbeat.Filter.Exclude[0].MatchString("/TMP/main.go"))
+	assert.Len(t, p.Heartbeat.Privacy.ProjectPatterns, 1)
+}
+
+func TestLoadParams_InvalidPattern(t *testing.T) {
+	tests := map[string]string{
+		"exclude":                    `invalid exclude pattern "[a-": error parsing regexp: missing closing ]: ` + "`[a-`",
+		"include":                    `invalid include pattern "[a-": error parsing regexp: missing closing ]: ` + "`[a-`",
+		"privacy.hide-file-names":    `invalid privacy.hide-file-names pattern "[a-": error parsing regexp: missing closing ]: ` + "`[a-`",
+		"privacy.hide-project-names": `invalid privacy.hide-project-names pattern "[a-": error parsing regexp: missing closing ]: ` + "`[a-`",
+	}
+
+	for key, expected := range tests {
+		t.Run(key, func(t *testing.T) {
+			v := viper.New()
+			v.Set("entity", "/home/user/src/main.go")
+			v.Set(key, []string{"^/tmp/", "[a-"})
+
+			_, err := params.LoadParams(t.Context(), v)
+			assert.EqualError(t, err, expected)
+		})
+	}
+}
+
+func TestLoadParams_Time(t *testing.T) {
+	tests := map[string]struct {
+		Time     string
+		Expected uint64
+	}{
+		"float seconds": {Time: "1585598059.1", Expected: 1585598059100},
+		"milliseconds":  {Time: "1585598059100", Expected: 1585598059100},
+		"rfc3339":       {Time: "2020-03-30T19:54:19.1Z", Expected: 1585598059100},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("entity", "/home/user/src/main.go")
+			v.Set("time", test.Time)
+
+			p, err := params.LoadParams(t.Context(), v)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, p.Heartbeat.Time)
+		})
+	}
+}
+
+func TestLoadParams_TimeDefaultsToNow(t *testing.T) {
+	for name, value := range map[string]string{"zero": "0", "zero float": "0.0", "empty": ""} {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("entity", "/home/user/src/main.go")
+			v.Set("time", value)
+
+			before := uint64(time.Now().UnixMilli())
+
+			p, err := params.LoadParams(t.Context(), v)
+			require.NoError(t, err)
+
+			assert.GreaterOrEqual(t, p.Heartbeat.Time, before)
+			assert.LessOrEqual(t, p.Heartbeat.Time, uint64(time.Now().UnixMilli()))
+		})
+	}
+}
+
+func TestLoadParams_ExtraHeartbeatsTime(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "extra.json")
+	require.NoError(t, os.WriteFile(fp, []byte(`[
+		{"entity": "/home/user/src/a.go", "time": 1585598059.1},
+		{"entity": "/home/user/src/b.go", "time": "2020-03-30T19:54:19.1Z"},
+		{"entity": "/home/user/src/c.go", "time": 1585598059100000},
+		{"entity": "/home/user/src/d.go", "time": 0},
+		{"entity": "/home/user/src/e.go"},
+		{"entity": "/home/user/src/f.go", "time": 1e11}
+	]`), 0644))
+
+	stdin, err := os.Open(fp)
+	require.NoError(t, err)
+	defer stdin.Close()
+
+	origStdin := os.Stdin
+	defer func() { os.Stdin = origStdin }()
+	os.Stdin = stdin
+
+	v := viper.New()
+	v.Set("entity", "/home/user/src/main.go")
+	v.Set("extra-heartbeats", true)
+
+	p, err := params.LoadParams(t.Context(), v)
+	require.NoError(t, err)
+
+	require.Len(t, p.Heartbeat.ExtraHeartbeats, 3)
+
+	for n, entity := range []string{"/home/user/src/a.go", "/home/user/src/b.go", "/home/user/src/c.go"} {
+		assert.Equal(t, entity, p.Heartbeat.ExtraHeartbeats[n].Entity)
+		assert.Equal(t, uint64(1585598059100), p.Heartbeat.ExtraHeartbeats[n].Time)
+	}
+}
+
+func TestLoadParams_Hostname(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/home/user/src/main.go")
+	v.Set("hostname", "build-agent")
+
+	p, err := params.LoadParams(t.Context(), v)
+	require.NoError(t, err)
+
+	require.NotNil(t, p.Heartbeat.Hostname)
+	assert.Equal(t, "build-agent", *p.Heartbeat.Hostname)
+}
+
+func TestLoadParams_HostnameOptOut(t *testing.T) {
+	v := viper.New()
+	v.Set("entity", "/home/user/src/main.go")
+	v.Set("hostname", "")
+
+	p, err := params.LoadParams(t.Context(), v)
+	require.NoError(t, err)
+
+	assert.Nil(t, p.Heartbeat.Hostname)
+}
+
+func TestLoadParams_ExtraHeartbeatsInvalidStdin(t *testing.T) {
+	tests := map[string]string{
+		"empty":     "",
+		"not array": `{"entity": "/home/user/src/a.go"}`,
+		"broken":    `[{"entity": `,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), "extra.json")
+			require.NoError(t, os.WriteFile(fp, []byte(content), 0644))
+
+			stdin, err := os.Open(fp)
+			require.NoError(t, err)
+			defer stdin.Close()
+
+			origStdin := os.Stdin
+			defer func() { os.Stdin = origStdin }()
+			os.Stdin = stdin
+
+			v := viper.New()
+			v.Set("entity", "/home/user/src/main.go")
+			v.Set("extra-heartbeats", true)
+
+			p, err := params.LoadParams(t.Context(), v)
+			require.NoError(t, err)
+
+			assert.Equal(t, "/home/user/src/main.go", p.Heartbeat.Entity)
+			assert.Empty(t, p.Heartbeat.ExtraHeartbeats)
+		})
+	}
+}